@@ -3,6 +3,58 @@ package echoswagger
 // CDN refer to https://www.jsdelivr.com/package/npm/swagger-ui-dist
 const DefaultCDN = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@3.44.1"
 
+// CDN refer to https://www.jsdelivr.com/package/npm/redoc
+const DefaultReDocCDN = "https://cdn.jsdelivr.net/npm/redoc@2.0.0-rc.53/bundles"
+
+// CDN refer to https://www.jsdelivr.com/package/npm/rapidoc
+const DefaultRapiDocCDN = "https://cdn.jsdelivr.net/npm/rapidoc@9.0.0/dist"
+
+// specLoaderContent sets specUrl, redirectUrl and the embedded spec
+// for the doc pages.
+const specLoaderContent = `{{define "loadSpec"}}
+      var specPath = "{{.specName}}"
+      var redirectPath = "{{.oauth2RedirectName}}"
+      if (!window.location.pathname.endsWith("/")) {
+        specPath = "/" + specPath
+        redirectPath = "/" + redirectPath
+      }
+      var specUrl = window.location.origin+window.location.pathname+specPath
+      var redirectUrl = window.location.origin+window.location.pathname+redirectPath
+      var specStr = "{{.spec}}"
+      var spec = specStr ? JSON.parse(specStr) : undefined
+      if (spec && !spec.host) {
+        spec.host = window.location.host
+      }
+      if (spec && !spec.basePath) {
+        var docPath = "{{.docPath}}"
+        var basePath = window.location.pathname
+        if (!docPath.endsWith("/")) { docPath += "/" }
+        if (!basePath.endsWith("/")) { basePath += "/" }
+        if (basePath.endsWith(docPath)) {
+          basePath = basePath.slice(0, -docPath.length)
+        }
+        spec.basePath = basePath
+      }
+{{end}}`
+
+// brandingContent renders the branding of UISetting for the doc pages.
+const brandingContent = `{{define "head"}}
+    {{if .favicon}}<link rel="icon" href="{{.favicon}}" />{{end}}
+    {{if .css}}<style{{if .nonce}} nonce="{{.nonce}}"{{end}}>{{.css}}</style>{{end}}
+    {{.head}}
+{{end}}
+{{define "header"}}
+    {{if or .logo .header}}<div class="echoswagger-header">
+      {{if .logo}}<img class="echoswagger-logo" src="{{.logo}}" alt="{{.title}}" />{{end}}
+      {{.header}}
+    </div>{{end}}
+{{end}}
+{{define "footer"}}
+    {{.footer}}
+    {{range .scripts}}<script src="{{.}}"{{if $.nonce}} nonce="{{$.nonce}}"{{end}}></script>
+    {{end}}
+{{end}}`
+
 const SwaggerUIContent = `{{define "swagger"}}
 <!DOCTYPE html>
 <html lang="en">
@@ -10,9 +62,9 @@ const SwaggerUIContent = `{{define "swagger"}}
     <meta charset="UTF-8">
     <title>{{.title}}</title>
     <link rel="stylesheet" type="text/css" href="{{.cdn}}/swagger-ui.css" />
-    <link rel="icon" type="image/png" href="{{.cdn}}/favicon-32x32.png" sizes="32x32" />
-    <link rel="icon" type="image/png" href="{{.cdn}}/favicon-16x16.png" sizes="16x16" />
-    <style>
+    {{if not .favicon}}<link rel="icon" type="image/png" href="{{.cdn}}/favicon-32x32.png" sizes="32x32" />
+    <link rel="icon" type="image/png" href="{{.cdn}}/favicon-16x16.png" sizes="16x16" />{{end}}
+    <style{{if .nonce}} nonce="{{.nonce}}"{{end}}>
       html
       {
         box-sizing: border-box;
@@ -38,35 +90,21 @@ const SwaggerUIContent = `{{define "swagger"}}
         display: none;
       }{{end}}
     </style>
+{{template "head" .}}
   </head>
 
   <body>
+{{template "header" .}}
     <div id="swagger-ui"></div>
 
-    <script src="{{.cdn}}/swagger-ui-bundle.js" crossorigin="anonymous"></script>
-    <script src="{{.cdn}}/swagger-ui-standalone-preset.js" crossorigin="anonymous"></script>
-    <script>
+    <script src="{{.cdn}}/swagger-ui-bundle.js" crossorigin="anonymous"{{if .nonce}} nonce="{{.nonce}}"{{end}}></script>
+    <script src="{{.cdn}}/swagger-ui-standalone-preset.js" crossorigin="anonymous"{{if .nonce}} nonce="{{.nonce}}"{{end}}></script>
+    <script{{if .nonce}} nonce="{{.nonce}}"{{end}}>
     window.onload = function() {
-      var specPath = "{{.specName}}"
-      if (!window.location.pathname.endsWith("/")) {
-        specPath = "/" + specPath
-      }
-      var specStr = "{{.spec}}"
-      var spec = specStr ? JSON.parse(specStr) : undefined
-      if (spec) {
-        spec.host = window.location.host
-        var docPath = "{{.docPath}}"
-        var basePath = window.location.pathname
-        if (!docPath.endsWith("/")) { docPath += "/" }
-        if (!basePath.endsWith("/")) { basePath += "/" }
-        if (basePath.endsWith(docPath)) {
-          basePath = basePath.slice(0, -docPath.length)
-        }
-        spec.basePath = basePath
-      }
+{{template "loadSpec" .}}
       // Begin Swagger UI call region
-      const ui = SwaggerUIBundle({
-        url: window.location.origin+window.location.pathname+specPath,
+      const ui = SwaggerUIBundle(Object.assign({
+        url: specUrl,
         spec: spec,
         dom_id: '#swagger-ui',
         deepLinking: true,
@@ -77,13 +115,195 @@ const SwaggerUIContent = `{{define "swagger"}}
         plugins: [
           SwaggerUIBundle.plugins.DownloadUrl
         ],
-        layout: "StandaloneLayout"
-      })
+        layout: "StandaloneLayout",
+        oauth2RedirectUrl: redirectUrl
+      }, {{.config}}))
       // End Swagger UI call region
-
+      {{if .oauth}}
+      ui.initOAuth({{.oauth}})
+      {{end}}
       window.ui = ui
     }
   </script>
+{{template "footer" .}}
+  </body>
+</html>
+{{end}}`
+
+const ReDocContent = `{{define "redoc"}}
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.title}}</title>
+    <style{{if .nonce}} nonce="{{.nonce}}"{{end}}>
+      body
+      {
+        margin: 0;
+        padding: 0;
+      }
+    </style>
+{{template "head" .}}
+  </head>
+
+  <body>
+{{template "header" .}}
+    <div id="redoc"></div>
+
+    <script src="{{.cdn}}/redoc.standalone.js" crossorigin="anonymous"{{if .nonce}} nonce="{{.nonce}}"{{end}}></script>
+    <script{{if .nonce}} nonce="{{.nonce}}"{{end}}>
+    window.onload = function() {
+{{template "loadSpec" .}}
+      Redoc.init(spec || specUrl, {{.config}}, document.getElementById("redoc"))
+    }
+  </script>
+{{template "footer" .}}
+  </body>
+</html>
+{{end}}`
+
+const RapiDocContent = `{{define "rapidoc"}}
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.title}}</title>
+{{template "head" .}}
+  </head>
+
+  <body>
+{{template "header" .}}
+    <rapi-doc id="rapidoc"></rapi-doc>
+
+    <script type="module" src="{{.cdn}}/rapidoc-min.js" crossorigin="anonymous"{{if .nonce}} nonce="{{.nonce}}"{{end}}></script>
+    <script{{if .nonce}} nonce="{{.nonce}}"{{end}}>
+    window.onload = function() {
+{{template "loadSpec" .}}
+      var doc = document.getElementById("rapidoc")
+      var config = {{.config}}
+      for (var k in config) {
+        doc.setAttribute(k, config[k])
+      }
+      if (spec) {
+        doc.loadSpec(spec)
+      } else {
+        doc.setAttribute("spec-url", specUrl)
+      }
+    }
+  </script>
+{{template "footer" .}}
+  </body>
+</html>
+{{end}}`
+
+// IndexContent lists doc pages of ApiRoots combined by Combine.
+const IndexContent = `{{define "index"}}
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.title}}</title>
+    <style{{if .nonce}} nonce="{{.nonce}}"{{end}}>
+      body
+      {
+        margin: 0;
+        padding: 24px;
+        font-family: sans-serif;
+      }
+    </style>
+{{template "head" .}}
+  </head>
+
+  <body>
+{{template "header" .}}
+    <h1>{{.title}}</h1>
+    <ul>
+      {{range .urls}}<li><a href="{{.DocURL}}">{{.Name}}</a> (<a href="{{.URL}}">{{$.specName}}</a>)</li>
+      {{end}}
+    </ul>
+{{template "footer" .}}
   </body>
 </html>
 {{end}}`
+
+// OAuth2RedirectContent is the page which passes the result of
+// OAuth2 authorization back to Swagger UI, copied from swagger-ui-dist.
+const OAuth2RedirectContent = `<!doctype html>
+<html lang="en-US">
+<head>
+    <title>Swagger UI: OAuth2 Redirect</title>
+</head>
+<body>
+<script>
+    'use strict';
+    function run () {
+        var oauth2 = window.opener.swaggerUIRedirectOauth2;
+        var sentState = oauth2.state;
+        var redirectUrl = oauth2.redirectUrl;
+        var isValid, qp, arr;
+
+        if (/code|token|error/.test(window.location.hash)) {
+            qp = window.location.hash.substring(1);
+        } else {
+            qp = location.search.substring(1);
+        }
+
+        arr = qp.split("&");
+        arr.forEach(function (v,i,_arr) { _arr[i] = '"' + v.replace('=', '":"') + '"';});
+        qp = qp ? JSON.parse('{' + arr.join() + '}',
+                function (key, value) {
+                    return key === "" ? value : decodeURIComponent(value);
+                }
+        ) : {};
+
+        isValid = qp.state === sentState;
+
+        if ((
+          oauth2.auth.schema.get("flow") === "accessCode" ||
+          oauth2.auth.schema.get("flow") === "authorizationCode" ||
+          oauth2.auth.schema.get("flow") === "authorization_code"
+        ) && !oauth2.auth.code) {
+            if (!isValid) {
+                oauth2.errCb({
+                    authId: oauth2.auth.name,
+                    source: "auth",
+                    level: "warning",
+                    message: "Authorization may be unsafe, passed state was changed in server Passed state wasn't returned from auth server"
+                });
+            }
+
+            if (qp.code) {
+                delete oauth2.state;
+                oauth2.auth.code = qp.code;
+                oauth2.callback({auth: oauth2.auth, redirectUrl: redirectUrl});
+            } else {
+                let oauthErrorMsg;
+                if (qp.error) {
+                    oauthErrorMsg = "["+qp.error+"]: " +
+                        (qp.error_description ? qp.error_description+ ". " : "no accessCode received from the server. ") +
+                        (qp.error_uri ? "More info: "+qp.error_uri : "");
+                }
+
+                oauth2.errCb({
+                    authId: oauth2.auth.name,
+                    source: "auth",
+                    level: "error",
+                    message: oauthErrorMsg || "[Authorization failed]: no accessCode received from the server"
+                });
+            }
+        } else {
+            oauth2.callback({auth: oauth2.auth, token: qp, isValid: isValid, redirectUrl: redirectUrl});
+        }
+        window.close();
+    }
+
+    window.addEventListener('DOMContentLoaded', function () {
+      run();
+    });
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,75 @@
+package echoswagger
+
+import "github.com/labstack/echo"
+
+// DefaultCombinedTitle is the title of doc page mounted by Combine
+const DefaultCombinedTitle = "API Documentation"
+
+// specURL is an entry of Swagger UI's urls
+type specURL struct {
+	URL    string `json:"url"`
+	Name   string `json:"name"`
+	DocURL string `json:"-"`
+}
+
+// Combine mounts a doc page at docPath on e which lists specs of roots by
+// their title and version. Swagger UI switches between the specs by its
+// dropdown, and the other renderers show an index page linking to the doc
+// pages of roots. Roots must be created by New, the spec and doc page
+// of each root are those mounted by New.
+func Combine(e *echo.Echo, docPath string, ui UISetting, roots []ApiRoot, m ...echo.MiddlewareFunc) {
+	if e == nil {
+		panic("echoswagger: invalid Echo instance")
+	}
+	if _, err := ui.config(); err != nil {
+		panic("echoswagger: invalid RawConfig of UISetting")
+	}
+	rs := make([]*Root, len(roots))
+	for i, root := range roots {
+		r, ok := root.(*Root)
+		if !ok || len(r.docMounts) == 0 {
+			panic("echoswagger: invalid ApiRoot")
+		}
+		rs[i] = r
+	}
+
+	h := func(c echo.Context) error {
+		urls := make([]*specURL, len(rs))
+		for i, r := range rs {
+			name := r.spec.Info.Title
+			if r.spec.Info.Version != "" {
+				name += " (" + r.spec.Info.Version + ")"
+			}
+			urls[i] = &specURL{
+				URL:    connectPath(r.docMounts[0].path, SpecName),
+				Name:   name,
+				DocURL: connectPath(r.docMounts[0].path),
+			}
+		}
+		params := map[string]interface{}{
+			"title":   DefaultCombinedTitle,
+			"urls":    urls,
+			"hideTop": false,
+		}
+		var name string
+		if renderer, _ := ui.renderer(); renderer != UISwaggerUI {
+			name = "index"
+		}
+		return ui.render(c, name, params)
+	}
+	routes := []*echo.Route{
+		e.GET(connectPath(docPath), h, m...),
+		e.GET(connectPath(docPath, OAuth2RedirectName), oauth2RedirectHandler, m...),
+	}
+	if assets := ui.assets(); assets != nil {
+		routes = append(routes, e.GET(connectPath(docPath, AssetsPath, "*"), func(c echo.Context) error {
+			return serveAssets(c, assets)
+		}, m...))
+	}
+	ownRoutes(e, routes...)
+	for _, r := range rs {
+		if r.echo == e {
+			r.addDocRoutes(routes...)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package echoswagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombine(t *testing.T) {
+	e := echo.New()
+	v1 := New(e, "v1/doc", &Info{Title: "Pets", Version: "1.0"})
+	v2 := New(e, "v2/doc/", &Info{Title: "Pets", Version: "2.0"})
+	admin := New(e, "admin/doc", &Info{Title: "Admin"})
+
+	Combine(e, "docs", UISetting{HideTop: true}, []ApiRoot{v1, v2, admin})
+	Combine(e, "index", UISetting{Renderer: UIReDoc}, []ApiRoot{v1, v2})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<title>API Documentation</title>`)
+	assert.Contains(t, rec.Body.String(), `{"urls":[{"url":"/v1/doc/swagger.json","name":"Pets (1.0)"},`+
+		`{"url":"/v2/doc/swagger.json","name":"Pets (2.0)"},{"url":"/admin/doc/swagger.json","name":"Admin"}]}`)
+	assert.NotContains(t, rec.Body.String(), "#swagger-ui>.swagger-container>.topbar")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/docs/oauth2-redirect.html", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/index", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<li><a href="/v1/doc">Pets (1.0)</a> (<a href="/v1/doc/swagger.json">swagger.json</a>)</li>`)
+	assert.Contains(t, rec.Body.String(), `<li><a href="/v2/doc/">Pets (2.0)</a> (<a href="/v2/doc/swagger.json">swagger.json</a>)</li>`)
+
+	t.Run("Undocumented", func(t *testing.T) {
+		e := echo.New()
+		r := New(e, "doc", nil).SetUndocumentedRoutes(UndocumentedFail)
+		Combine(e, "docs", UISetting{}, []ApiRoot{r})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/swagger.json", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	assert.Panics(t, func() {
+		Combine(e, "invalid", UISetting{}, []ApiRoot{nil})
+	})
+}
@@ -57,6 +57,22 @@ func toSwaggerPath(path string) string {
 	return connectPath(path)
 }
 
+// pathTemplate removes names of parameters from a swagger path,
+// e.g. "/users/{id}" → "/users/{}"
+func pathTemplate(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		b.WriteByte(path[i])
+		if path[i] == '{' {
+			for i < len(path) && path[i] != '}' {
+				i++
+			}
+			b.WriteByte('}')
+		}
+	}
+	return b.String()
+}
+
 func converter(t reflect.Type) func(s string) (interface{}, error) {
 	st, sf := toSwaggerType(t)
 	if st == "integer" && sf == "int32" {
@@ -0,0 +1,59 @@
+package echoswagger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+//go:generate go run gen_assets.go -dir swagger-ui-dist
+
+// SwaggerUIDist serves the files of swagger-ui-dist embedded in the binary,
+// it's the default UISetting.Assets of Swagger UI.
+var SwaggerUIDist http.FileSystem = swaggerUIDistFiles
+
+// distFS is a http.FileSystem of gzipped files keyed by their names
+type distFS map[string]string
+
+func (fs distFS) Open(name string) (http.File, error) {
+	data, ok := fs[strings.TrimPrefix(path.Clean("/"+name), "/")]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	r, err := gzip.NewReader(strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &distFile{Reader: bytes.NewReader(b), name: path.Base(name)}, nil
+}
+
+// distFile is a file of distFS, which is also its own os.FileInfo
+type distFile struct {
+	*bytes.Reader
+	name string
+}
+
+func (f *distFile) Close() error { return nil }
+
+func (f *distFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *distFile) Stat() (os.FileInfo, error) { return f, nil }
+
+func (f *distFile) Name() string       { return f.name }
+func (f *distFile) Size() int64        { return f.Reader.Size() }
+func (f *distFile) Mode() os.FileMode  { return 0444 }
+func (f *distFile) ModTime() time.Time { return time.Time{} }
+func (f *distFile) IsDir() bool        { return false }
+func (f *distFile) Sys() interface{}   { return nil }
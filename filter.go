@@ -0,0 +1,151 @@
+package echoswagger
+
+// docKey normalizes docPath as the key of docAudiences
+func docKey(docPath string) string {
+	return removeTrailingSlash(connectPath(docPath))
+}
+
+// filterSpec returns the spec for docPath without hidden operations and
+// operations which are not for the audiences of docPath. Definitions
+// which are only used by the removed operations are pruned.
+func (r *Root) filterSpec(docPath string) Swagger {
+	if len(r.hidden) == 0 && len(r.audiences) == 0 {
+		return *r.spec
+	}
+	audiences, ok := r.docAudiences[docKey(docPath)]
+	visible := func(o *Operation) bool {
+		if r.hidden[o] {
+			return false
+		}
+		labels := r.audiences[o]
+		if !ok || len(labels) == 0 {
+			return true
+		}
+		for _, l := range labels {
+			if contains(audiences, l) {
+				return true
+			}
+		}
+		return false
+	}
+
+	spec := *r.spec
+	spec.Paths = make(map[string]interface{})
+	refs := make(map[string]bool)
+	tags := make(map[string]bool)
+	for k, v := range r.spec.Paths {
+		p := v.(*Path).filter(visible)
+		if p == nil {
+			continue
+		}
+		spec.Paths[k] = p
+		for _, o := range p.operations() {
+			for _, t := range o.Tags {
+				tags[t] = true
+			}
+			for _, pm := range o.Parameters {
+				collectRefs(pm.Schema, r.spec.Definitions, refs)
+			}
+			for _, resp := range o.Responses {
+				collectRefs(resp.Schema, r.spec.Definitions, refs)
+			}
+		}
+	}
+	for _, pm := range r.spec.Parameters {
+		collectRefs(pm.Schema, r.spec.Definitions, refs)
+	}
+	for _, resp := range r.spec.Responses {
+		collectRefs(resp.Schema, r.spec.Definitions, refs)
+	}
+
+	spec.Definitions = make(map[string]*JSONSchema)
+	for k, v := range r.spec.Definitions {
+		if refs[k] {
+			spec.Definitions[k] = v
+		}
+	}
+	spec.Tags = nil
+	for _, t := range r.spec.Tags {
+		if tags[t.Name] {
+			spec.Tags = append(spec.Tags, t)
+		}
+	}
+	spec.TagGroups = nil
+	for _, tg := range r.spec.TagGroups {
+		g := &TagGroup{Name: tg.Name}
+		for _, t := range tg.Tags {
+			if tags[t] {
+				g.Tags = append(g.Tags, t)
+			}
+		}
+		if len(g.Tags) > 0 {
+			spec.TagGroups = append(spec.TagGroups, g)
+		}
+	}
+	return spec
+}
+
+// operations returns all operations of the path
+func (p *Path) operations() []*Operation {
+	var ops []*Operation
+	for _, o := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch} {
+		if o != nil {
+			ops = append(ops, o)
+		}
+	}
+	for _, e := range p.Extensions {
+		if o, ok := e.(*Operation); ok {
+			ops = append(ops, o)
+		}
+	}
+	return ops
+}
+
+// filter returns a copy of the path with operations which keep reports true,
+// returns nil if there are no operations left
+func (p *Path) filter(keep func(*Operation) bool) *Path {
+	np := *p
+	for _, o := range []**Operation{&np.Get, &np.Put, &np.Post, &np.Delete, &np.Options, &np.Head, &np.Patch} {
+		if *o != nil && !keep(*o) {
+			*o = nil
+		}
+	}
+	if p.Extensions != nil {
+		np.Extensions = make(map[string]interface{})
+		for k, e := range p.Extensions {
+			if o, ok := e.(*Operation); ok && !keep(o) {
+				continue
+			}
+			np.Extensions[k] = e
+		}
+	}
+	if len(np.operations()) == 0 {
+		return nil
+	}
+	return &np
+}
+
+// collectRefs collects keys of definitions which are referenced by s recursively
+func collectRefs(s *JSONSchema, defs map[string]*JSONSchema, refs map[string]bool) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		key := s.Ref[len(DefPrefix):]
+		if !refs[key] {
+			refs[key] = true
+			collectRefs(defs[key], defs, refs)
+		}
+	}
+	collectRefs(s.Items, defs, refs)
+	collectRefs(s.AdditionalProperties, defs, refs)
+	for _, p := range s.Properties {
+		collectRefs(p, defs, refs)
+	}
+	for _, d := range s.Definitions {
+		collectRefs(d, defs, refs)
+	}
+	for _, a := range s.AnyOf {
+		collectRefs(a, defs, refs)
+	}
+}
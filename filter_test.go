@@ -0,0 +1,86 @@
+package echoswagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudience(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name"`
+	}
+	type Audit struct {
+		Operator string `json:"operator"`
+	}
+	type Secret struct {
+		Key string `json:"key"`
+	}
+
+	r := prepareApiRoot()
+	r.AddDocPath("partner/doc", []string{"partner"}).
+		AddDocPath("internal/doc", []string{"internal"})
+	var h echo.HandlerFunc
+	r.GET("/pets", h).AddResponse(http.StatusOK, "pets", []Pet{}, nil)
+	r.GET("/secret", h).AddResponse(http.StatusOK, "secret", Secret{}, nil).SetHidden()
+	r.POST("/partner", h).SetAudience("partner")
+	g := r.Group("Admin", "/admin").SetAudience("internal")
+	g.GET("/audits", h).AddResponse(http.StatusOK, "audits", []Audit{}, nil)
+	g.GET("/partners", h).SetAudience("partner", "internal")
+	r.Group("Hidden", "/hidden").SetHidden().GET("", h)
+
+	specPaths := func(docPath string) (Swagger, []string) {
+		e := r.(*Root).echo
+		if e == nil {
+			e = echo.New()
+		}
+		c := e.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		s, err := r.(*Root).GetSpec(c, docPath)
+		assert.NoError(t, err)
+		var paths []string
+		for k := range s.Paths {
+			paths = append(paths, k)
+		}
+		return s, paths
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		s, paths := specPaths("/doc")
+		assert.ElementsMatch(t, []string{"/pets", "/partner", "/admin/audits", "/admin/partners"}, paths)
+		assert.Len(t, s.Definitions, 2)
+		assert.NotContains(t, s.Definitions, "Secret")
+		assert.Len(t, s.Tags, 1)
+	})
+
+	t.Run("Partner", func(t *testing.T) {
+		s, paths := specPaths("/partner/doc/")
+		assert.ElementsMatch(t, []string{"/pets", "/partner", "/admin/partners"}, paths)
+		assert.Len(t, s.Definitions, 1)
+		assert.Contains(t, s.Definitions, "Pet")
+	})
+
+	t.Run("Internal", func(t *testing.T) {
+		s, paths := specPaths("internal/doc")
+		assert.ElementsMatch(t, []string{"/pets", "/admin/audits", "/admin/partners"}, paths)
+		assert.Len(t, s.Definitions, 2)
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		assert.Len(t, r.(*Root).spec.Paths, 6)
+		assert.Len(t, r.(*Root).spec.Definitions, 3)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		e := echo.New()
+		c := e.NewContext(httptest.NewRequest(echo.GET, "/partner/doc/swagger.json", nil), httptest.NewRecorder())
+		if assert.NoError(t, r.(*Root).specHandler("partner/doc")(c)) {
+			var s Swagger
+			assert.NoError(t, json.Unmarshal(c.Response().Writer.(*httptest.ResponseRecorder).Body.Bytes(), &s))
+			assert.Len(t, s.Paths, 3)
+		}
+	})
+}
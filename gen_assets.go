@@ -0,0 +1,85 @@
+//go:build ignore
+// +build ignore
+
+// gen_assets.go generates dist_files.go from the files of swagger-ui-dist,
+// which are compressed by gzip and embedded as string literals.
+//
+//	go run gen_assets.go -dir path/to/swagger-ui-dist [-version x.y.z]
+//
+// The version is read from package.json of dir if it's not given.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+// files are used by the Swagger UI doc page
+var files = []string{
+	"favicon-16x16.png",
+	"favicon-32x32.png",
+	"swagger-ui-bundle.js",
+	"swagger-ui-standalone-preset.js",
+	"swagger-ui.css",
+}
+
+func main() {
+	dir := flag.String("dir", "swagger-ui-dist", "directory of swagger-ui-dist")
+	version := flag.String("version", "", "version of swagger-ui-dist")
+	out := flag.String("o", "dist_files.go", "output file")
+	flag.Parse()
+
+	if *version == "" {
+		b, err := ioutil.ReadFile(filepath.Join(*dir, "package.json"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(b, &pkg); err != nil {
+			log.Fatal(err)
+		}
+		*version = pkg.Version
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprint(buf, "// Code generated by \"go run gen_assets.go\"; DO NOT EDIT.\n\n")
+	fmt.Fprint(buf, "// The files are from swagger-ui-dist, which is licensed under the\n")
+	fmt.Fprint(buf, "// Apache License 2.0, see https://github.com/swagger-api/swagger-ui.\n\n")
+	fmt.Fprint(buf, "package echoswagger\n\n")
+	fmt.Fprint(buf, "// SwaggerUIDistVersion is the version of swagger-ui-dist served by SwaggerUIDist.\n")
+	fmt.Fprintf(buf, "const SwaggerUIDistVersion = %q\n\n", *version)
+	fmt.Fprint(buf, "var swaggerUIDistFiles = distFS{\n")
+	for _, name := range files {
+		b, err := ioutil.ReadFile(filepath.Join(*dir, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+		z := new(bytes.Buffer)
+		w, _ := gzip.NewWriterLevel(z, gzip.BestCompression)
+		if _, err := w.Write(b); err != nil {
+			log.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(buf, "\t%q: %q,\n", name, z.String())
+	}
+	fmt.Fprint(buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -95,7 +95,7 @@ func (r *RawDefineDic) genSchema(v reflect.Value) *JSONSchema {
 	return schema
 }
 
-func (api) genHeader(v reflect.Value) map[string]*Header {
+func genHeader(v reflect.Value) map[string]*Header {
 	rt := indirect(v).Type()
 	if rt.Kind() != reflect.Struct {
 		return nil
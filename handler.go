@@ -0,0 +1,231 @@
+package echoswagger
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+
+	"github.com/labstack/echo"
+)
+
+var (
+	contextType = reflect.TypeOf((*echo.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Handle registers a typed handler fn for method and path on r, fn must be
+// one of:
+//
+//	func(c echo.Context, in *In) (Out, error)
+//	func(c echo.Context, in *In) error
+//	func(c echo.Context) (Out, error)
+//
+// Fields of In with `param`, `query` or `form` tags are documented as path,
+// query or formData parameters, and In is documented as the body otherwise.
+// For GET, DELETE and HEAD, In is documented as query parameters instead of body.
+// Requests are bound to In by `echo.Context#Bind` and path & query fields,
+// and validated by `echo.Context#Validate` if the Echo has a Validator.
+// Out is written as JSON with status 200 and documented as the 200 response,
+// handlers without Out respond 204.
+func Handle(r ApiRouter, method, path string, fn interface{}, m ...echo.MiddlewareFunc) Api {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || ft.NumIn() < 1 || ft.NumIn() > 2 || ft.In(0) != contextType ||
+		ft.NumOut() < 1 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		panic("echoswagger: invalid typed handler " + ft.String())
+	}
+	var inType reflect.Type
+	if ft.NumIn() == 2 {
+		inType = ft.In(1)
+		if inType.Kind() != reflect.Ptr || inType.Elem().Kind() != reflect.Struct {
+			panic("echoswagger: input of typed handler must be a pointer to struct")
+		}
+		inType = inType.Elem()
+	}
+
+	h := func(c echo.Context) error {
+		args := []reflect.Value{reflect.ValueOf(c)}
+		if inType != nil {
+			in := reflect.New(inType)
+			if err := bindTyped(c, in.Interface()); err != nil {
+				return err
+			}
+			if c.Echo().Validator != nil {
+				if err := c.Validate(in.Interface()); err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+				}
+			}
+			args = append(args, in)
+		}
+		outs := fv.Call(args)
+		if err, _ := outs[len(outs)-1].Interface().(error); err != nil {
+			return err
+		}
+		if len(outs) == 1 {
+			return c.NoContent(http.StatusNoContent)
+		}
+		return c.JSON(http.StatusOK, outs[0].Interface())
+	}
+
+	a := r.Match([]string{method}, path, h, m...)
+	name := runtime.FuncForPC(fv.Pointer()).Name()
+	for _, ap := range a.(multiApi) {
+		ap.route.Name = name
+		if inType != nil {
+			ap.addTypedParams(inType, method)
+		}
+	}
+	if ft.NumOut() == 2 {
+		a.AddResponse(http.StatusOK, "successful operation", reflect.Zero(ft.Out(0)).Interface(), nil)
+	} else {
+		a.AddResponse(http.StatusNoContent, "successful operation", nil, nil)
+	}
+	return a
+}
+
+// addTypedParams documents fields of rt as parameters, fields without
+// location tags are documented as the body of methods other than
+// GET, DELETE and HEAD.
+func (a *api) addTypedParams(rt reflect.Type, method string) {
+	located, form := false, false
+	var fields []reflect.StructField
+	eachField(rt, func(f reflect.StructField) {
+		in, ok := fieldLocation(f)
+		if !ok {
+			fields = append(fields, f)
+			return
+		}
+		located = true
+		form = form || in == ParamInFormData
+		if pm := (Parameter{}).generate(f, in); pm != nil {
+			// path params are named by the tag which echo binds them with
+			if name := f.Tag.Get("param"); in == ParamInPath && name != "" {
+				pm.Name = name
+			}
+			pm.Name = a.operation.rename(pm.Name)
+			a.operation.Parameters = append(a.operation.Parameters, pm)
+		}
+	})
+	bodyless := method == echo.GET || method == echo.DELETE || method == echo.HEAD
+	if !located {
+		p := reflect.New(rt).Interface()
+		if bodyless {
+			a.operation.addParams(p, ParamInQuery, "", "", false, true)
+		} else {
+			a.addBodyParams(p, "body", "", true)
+		}
+		return
+	}
+	// body and formData parameters can not be used together
+	if bodyless || form || len(fields) == 0 {
+		return
+	}
+	for i := range fields {
+		fields[i].Index = nil
+		fields[i].Offset = 0
+	}
+	// named apart from rt, which may be used as a response as well
+	v := reflect.New(reflect.StructOf(fields)).Elem()
+	a.operation.Parameters = append(a.operation.Parameters, &Parameter{
+		Name:     "body",
+		In:       string(ParamInBody),
+		Required: true,
+		Schema:   &JSONSchema{Ref: DefPrefix + a.defs.addNamedDefinition(v, rt.Name()+"Body")},
+	})
+}
+
+// fieldLocation returns the location of field by its tags
+func fieldLocation(f reflect.StructField) (ParamInType, bool) {
+	if _, ok := f.Tag.Lookup("param"); ok {
+		return ParamInPath, true
+	}
+	if _, ok := f.Tag.Lookup("query"); ok {
+		return ParamInQuery, true
+	}
+	if _, ok := f.Tag.Lookup("form"); ok {
+		return ParamInFormData, true
+	}
+	return "", false
+}
+
+func eachField(rt reflect.Type, fn func(f reflect.StructField)) {
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			eachField(f.Type, fn)
+		} else if f.PkgPath == "" {
+			fn(f)
+		}
+	}
+}
+
+// bindTyped binds request to in, path and query fields are bound for all
+// methods since the default binder only binds query of GET and DELETE requests.
+func bindTyped(c echo.Context, in interface{}) error {
+	req := c.Request()
+	if req.ContentLength != 0 || req.Method == echo.GET || req.Method == echo.DELETE {
+		if err := c.Bind(in); err != nil {
+			return err
+		}
+	}
+
+	params := make(map[string]string)
+	for _, n := range c.ParamNames() {
+		params[n] = c.Param(n)
+	}
+	query := c.QueryParams()
+	var err error
+	rv := reflect.ValueOf(in).Elem()
+	eachField(rv.Type(), func(f reflect.StructField) {
+		if err != nil {
+			return
+		}
+		var values []string
+		if name := f.Tag.Get("param"); name != "" {
+			if v, ok := params[name]; ok {
+				values = []string{v}
+			}
+		} else if name := f.Tag.Get("query"); name != "" {
+			values = query[name]
+		}
+		if len(values) > 0 {
+			err = setField(rv.FieldByIndex(f.Index), values)
+		}
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+// setField converts values to the type of field and sets it
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Ptr {
+		v := reflect.New(field.Type().Elem())
+		if err := setField(v.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+	if field.Kind() == reflect.Slice {
+		s := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setField(s.Index(i), []string{v}); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	}
+	v, err := converter(field.Type())(values[0])
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().ConvertibleTo(field.Type()) {
+		return nil
+	}
+	field.Set(rv.Convert(field.Type()))
+	return nil
+}
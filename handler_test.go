@@ -0,0 +1,156 @@
+package echoswagger
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type typedPet struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type typedCreatePetReq struct {
+	Name string `json:"name" swagger:"required"`
+}
+
+type typedGetPetReq struct {
+	Id     int64    `param:"id"`
+	Fields []string `query:"fields"`
+}
+
+type typedUpdatePetReq struct {
+	Id   int64  `param:"id"`
+	Name string `json:"name"`
+}
+
+type typedListPetsReq struct {
+	Limit int `swagger:"max(100)"`
+}
+
+type typedValidator struct{}
+
+func (typedValidator) Validate(i interface{}) error {
+	if r, ok := i.(*typedCreatePetReq); ok && r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func createPet(c echo.Context, in *typedCreatePetReq) (*typedPet, error) {
+	return &typedPet{Id: 1, Name: in.Name}, nil
+}
+
+func TestHandle(t *testing.T) {
+	e := echo.New()
+	e.Validator = typedValidator{}
+	r := New(e, "doc/", nil).SetOperationIDFunc(OperationIDFromHandler)
+	g := r.Group("Pets", "/pets")
+
+	Handle(g, echo.POST, "", createPet)
+	Handle(g, echo.GET, "/:id", func(c echo.Context, in *typedGetPetReq) (*typedPet, error) {
+		if in.Id == 0 {
+			return nil, echo.ErrNotFound
+		}
+		return &typedPet{Id: in.Id, Name: strings.Join(in.Fields, ",")}, nil
+	})
+	Handle(g, echo.PUT, "/:id", func(c echo.Context, in *typedUpdatePetReq) (*typedUpdatePetReq, error) {
+		return in, nil
+	})
+	Handle(g, echo.GET, "", func(c echo.Context, in *typedListPetsReq) ([]typedPet, error) {
+		return make([]typedPet, in.Limit), nil
+	})
+	Handle(g, echo.DELETE, "/:id", func(c echo.Context) error {
+		return nil
+	})
+
+	assert.Panics(t, func() {
+		Handle(g, echo.GET, "/invalid", func(in *typedGetPetReq) error { return nil })
+	})
+	assert.Panics(t, func() {
+		Handle(g, echo.GET, "/invalid", func(c echo.Context, in typedGetPetReq) error { return nil })
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		tests := []struct {
+			method string
+			target string
+			body   string
+			code   int
+			resp   string
+		}{
+			{echo.POST, "/pets", `{"name":"Tom"}`, http.StatusOK, `{"id":1,"name":"Tom"}`},
+			{echo.POST, "/pets", `{}`, http.StatusBadRequest, `{"message":"name is required"}`},
+			{echo.GET, "/pets/2?fields=a&fields=b", "", http.StatusOK, `{"id":2,"name":"a,b"}`},
+			{echo.PUT, "/pets/3", `{"name":"Jerry"}`, http.StatusOK, `{"Id":3,"name":"Jerry"}`},
+			{echo.GET, "/pets/0", "", http.StatusNotFound, `{"message":"Not Found"}`},
+			{echo.GET, "/pets?Limit=2", "", http.StatusOK, `[{"id":0,"name":""},{"id":0,"name":""}]`},
+			{echo.DELETE, "/pets/1", "", http.StatusNoContent, ``},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code, tt.target)
+			assert.Equal(t, tt.resp, strings.TrimSpace(rec.Body.String()), tt.target)
+		}
+	})
+
+	t.Run("Spec", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest(echo.GET, "/doc/swagger.json", nil), httptest.NewRecorder())
+		spec, err := r.(*Root).GetSpec(c, "doc/")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		create := spec.Paths["/pets"].(*Path).Post
+		assert.Equal(t, "createPet", create.OperationID)
+		assert.Equal(t, []string{"Pets"}, create.Tags)
+		if assert.Len(t, create.Parameters, 1) {
+			assert.Equal(t, "body", create.Parameters[0].In)
+			assert.Equal(t, DefPrefix+"typedCreatePetReq", create.Parameters[0].Schema.Ref)
+		}
+		assert.Equal(t, DefPrefix+"typedPet", create.Responses["200"].Schema.Ref)
+
+		get := spec.Paths["/pets/{id}"].(*Path).Get
+		if assert.Len(t, get.Parameters, 2) {
+			assert.Equal(t, &Parameter{Name: "id", In: "path", Required: true, Type: "integer", Format: "int64"}, get.Parameters[0])
+			assert.Equal(t, "fields", get.Parameters[1].Name)
+			assert.Equal(t, "query", get.Parameters[1].In)
+		}
+
+		update := spec.Paths["/pets/{id}"].(*Path).Put
+		if assert.Len(t, update.Parameters, 2) {
+			assert.Equal(t, "id", update.Parameters[0].Name)
+			assert.Equal(t, "path", update.Parameters[0].In)
+			assert.Equal(t, "body", update.Parameters[1].In)
+			assert.Equal(t, DefPrefix+"typedUpdatePetReqBody", update.Parameters[1].Schema.Ref)
+		}
+		if def := spec.Definitions["typedUpdatePetReqBody"]; assert.NotNil(t, def) {
+			assert.Len(t, def.Properties, 1)
+			assert.Contains(t, def.Properties, "name")
+		}
+		assert.Equal(t, DefPrefix+"typedUpdatePetReq", update.Responses["200"].Schema.Ref)
+		if def := spec.Definitions["typedUpdatePetReq"]; assert.NotNil(t, def) {
+			assert.Len(t, def.Properties, 2)
+		}
+
+		list := spec.Paths["/pets"].(*Path).Get
+		if assert.Len(t, list.Parameters, 1) {
+			assert.Equal(t, "Limit", list.Parameters[0].Name)
+			assert.Equal(t, "query", list.Parameters[0].In)
+		}
+		assert.Equal(t, "array", string(list.Responses["200"].Schema.Type))
+
+		del := spec.Paths["/pets/{id}"].(*Path).Delete
+		assert.Empty(t, del.Parameters)
+		assert.Contains(t, del.Responses, "204")
+	})
+}
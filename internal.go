@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
+	"path"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/labstack/echo"
 )
@@ -20,10 +23,300 @@ const (
 	ParamInBody     ParamInType = "body"
 )
 
+// UndocumentedMode decides how to handle routes which are not registered by echoswagger.
+type UndocumentedMode string
+
+const (
+	// UndocumentedIgnore leaves undocumented routes out of the spec.
+	UndocumentedIgnore UndocumentedMode = "ignore"
+	// UndocumentedWarn logs undocumented routes by the logger of Echo.
+	UndocumentedWarn UndocumentedMode = "warn"
+	// UndocumentedFail fails the spec generation if there are undocumented routes.
+	UndocumentedFail UndocumentedMode = "fail"
+	// UndocumentedInclude adds undocumented routes to the spec with tag "undocumented".
+	UndocumentedInclude UndocumentedMode = "include"
+)
+
+// UndocumentedTag is the tag of undocumented routes added by UndocumentedInclude.
+const UndocumentedTag = "undocumented"
+
+// UIRenderer is the renderer of doc page
+type UIRenderer string
+
+const (
+	UISwaggerUI UIRenderer = "swagger"
+	UIReDoc     UIRenderer = "redoc"
+	UIRapiDoc   UIRenderer = "rapidoc"
+)
+
 type UISetting struct {
 	DetachSpec bool
 	HideTop    bool
-	CDN        string
+	// Renderer selects the renderer of doc page, default is UISwaggerUI.
+	// HideTop, OAuth and the options of Swagger UI only apply to UISwaggerUI,
+	// RawConfig is passed to the other renderers as their options.
+	Renderer UIRenderer
+	// CDN is the url of renderer files, it overrides Assets,
+	// e.g. DefaultCDN loads Swagger UI from the CDN.
+	CDN string
+	// Assets serves renderer files under "{docPath}/assets/" instead of CDN,
+	// default is SwaggerUIDist for UISwaggerUI, which is embedded in the binary.
+	Assets http.FileSystem
+	// OAuth initializes OAuth2 authorization of Swagger UI.
+	OAuth *OAuthSetting
+
+	// Template replaces the template of doc page, it's executed with
+	// "title", "cdn", "specName", "docPath", "spec", "hideTop", "config",
+	// "oauth", "oauth2RedirectName" and the branding below.
+	Template *template.Template
+	// Logo is the url of logo shown in the header.
+	Logo string
+	// Favicon is the url of favicon.
+	Favicon string
+	// CSS is added to the head as style.
+	CSS template.CSS
+	// HeadHTML is added to the head, e.g. meta tags.
+	HeadHTML template.HTML
+	// HeaderHTML is shown above the renderer.
+	HeaderHTML template.HTML
+	// FooterHTML is shown below the renderer.
+	FooterHTML template.HTML
+	// Scripts are urls of extra scripts, e.g. analytics.
+	Scripts []string
+	// Nonce returns the CSP nonce of request, which is set
+	// to the scripts and styles of doc page.
+	Nonce func(c echo.Context) string
+
+	// The following fields are options of Swagger UI, zero values
+	// keep the defaults of Swagger UI.
+	// See https://swagger.io/docs/open-source-tools/swagger-ui/usage/configuration/
+
+	// DeepLinking disables deep linking if it is false, default is true.
+	DeepLinking *bool
+	// Layout is the name of layout, default is "StandaloneLayout".
+	Layout string
+	// DocExpansion is the default expansion of operations and tags,
+	// "list", "full" or "none".
+	DocExpansion string
+	// DefaultModelsExpandDepth is the default expansion depth for models,
+	// -1 hides models.
+	DefaultModelsExpandDepth *int
+	// Filter enables filtering by tags.
+	Filter bool
+	// PersistAuthorization keeps authorization data after reloading.
+	PersistAuthorization bool
+	// DisplayOperationId shows operationIds of operations.
+	DisplayOperationId bool
+	// TryItOutEnabled enables "Try it out" by default.
+	TryItOutEnabled bool
+	// SupportedSubmitMethods lists HTTP methods which have "Try it out"
+	// enabled, an empty non-nil list disables "Try it out".
+	SupportedSubmitMethods []string
+	// OperationsSorter sorts operations, "alpha" or "method".
+	OperationsSorter string
+	// TagsSorter sorts tags, "alpha".
+	TagsSorter string
+	// RequestSnippetsEnabled shows request snippets.
+	RequestSnippetsEnabled bool
+	// ValidatorUrl is the url of spec validator, a pointer to
+	// empty string disables the validator.
+	ValidatorUrl *string
+	// RawConfig is a JSON object of Swagger UI options, which overrides
+	// the fields above.
+	RawConfig json.RawMessage
+}
+
+// branding adds the branding of UISetting to params of template
+func (ui UISetting) branding(c echo.Context, params map[string]interface{}) {
+	params["logo"] = ui.Logo
+	params["favicon"] = ui.Favicon
+	params["css"] = ui.CSS
+	params["head"] = ui.HeadHTML
+	params["header"] = ui.HeaderHTML
+	params["footer"] = ui.FooterHTML
+	params["scripts"] = ui.Scripts
+	if ui.Nonce != nil {
+		params["nonce"] = ui.Nonce(c)
+	}
+}
+
+// renderer returns the selected renderer and its default CDN
+func (ui UISetting) renderer() (UIRenderer, string) {
+	switch ui.Renderer {
+	case UIReDoc:
+		return UIReDoc, DefaultReDocCDN
+	case UIRapiDoc:
+		return UIRapiDoc, DefaultRapiDocCDN
+	default:
+		return UISwaggerUI, DefaultCDN
+	}
+}
+
+// assets returns the file system of renderer files, it returns nil
+// if renderer files are loaded from CDN.
+func (ui UISetting) assets() http.FileSystem {
+	if ui.Assets != nil {
+		return ui.Assets
+	}
+	if renderer, _ := ui.renderer(); ui.CDN == "" && renderer == UISwaggerUI {
+		return SwaggerUIDist
+	}
+	return nil
+}
+
+// config returns options of the renderer set by UISetting
+func (ui UISetting) config() (map[string]interface{}, error) {
+	c := make(map[string]interface{})
+	if renderer, _ := ui.renderer(); renderer != UISwaggerUI {
+		return c, ui.rawConfig(c)
+	}
+	if ui.DeepLinking != nil {
+		c["deepLinking"] = *ui.DeepLinking
+	}
+	if ui.Layout != "" {
+		c["layout"] = ui.Layout
+	}
+	if ui.DocExpansion != "" {
+		c["docExpansion"] = ui.DocExpansion
+	}
+	if ui.DefaultModelsExpandDepth != nil {
+		c["defaultModelsExpandDepth"] = *ui.DefaultModelsExpandDepth
+	}
+	if ui.Filter {
+		c["filter"] = true
+	}
+	if ui.PersistAuthorization {
+		c["persistAuthorization"] = true
+	}
+	if ui.DisplayOperationId {
+		c["displayOperationId"] = true
+	}
+	if ui.TryItOutEnabled {
+		c["tryItOutEnabled"] = true
+	}
+	if ui.SupportedSubmitMethods != nil {
+		c["supportedSubmitMethods"] = ui.SupportedSubmitMethods
+	}
+	if ui.OperationsSorter != "" {
+		c["operationsSorter"] = ui.OperationsSorter
+	}
+	if ui.TagsSorter != "" {
+		c["tagsSorter"] = ui.TagsSorter
+	}
+	if ui.RequestSnippetsEnabled {
+		c["requestSnippetsEnabled"] = true
+	}
+	if ui.ValidatorUrl != nil {
+		if *ui.ValidatorUrl == "" {
+			c["validatorUrl"] = nil
+		} else {
+			c["validatorUrl"] = *ui.ValidatorUrl
+		}
+	}
+	return c, ui.rawConfig(c)
+}
+
+// rawConfig merges RawConfig into c
+func (ui UISetting) rawConfig(c map[string]interface{}) error {
+	if len(ui.RawConfig) == 0 {
+		return nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(ui.RawConfig, &raw); err != nil {
+		return err
+	}
+	for k, v := range raw {
+		c[k] = v
+	}
+	return nil
+}
+
+// OAuthSetting is the config of Swagger UI's initOAuth.
+type OAuthSetting struct {
+	ClientId                          string   `json:"clientId,omitempty"`
+	Realm                             string   `json:"realm,omitempty"`
+	AppName                           string   `json:"appName,omitempty"`
+	Scopes                            []string `json:"scopes,omitempty"`
+	UsePkceWithAuthorizationCodeGrant bool     `json:"usePkceWithAuthorizationCodeGrant,omitempty"`
+}
+
+// OAuth2RedirectName is the name of OAuth2 redirect page under doc path
+const OAuth2RedirectName = "oauth2-redirect.html"
+
+// AssetsPath is the path of UISetting.Assets under doc path
+const AssetsPath = "assets"
+
+// docMount records a doc page mounted by New or AddDocPath
+type docMount struct {
+	path   string
+	m      []echo.MiddlewareFunc
+	assets bool
+}
+
+func (r *Root) mountDoc(docPath string, m []echo.MiddlewareFunc) {
+	r.addDocRoutes(
+		r.echo.GET(connectPath(docPath), r.docHandler(docPath), m...),
+		r.echo.GET(connectPath(docPath, SpecName), r.specHandler(docPath), m...),
+		r.echo.GET(connectPath(docPath, OAuth2RedirectName), oauth2RedirectHandler, m...),
+	)
+	d := &docMount{path: docPath, m: m}
+	r.docMounts = append(r.docMounts, d)
+	r.mountAssets(d)
+}
+
+// addDocRoutes records routes of doc pages, which are owned by r
+// and skipped by EnforceSecurity
+func (r *Root) addDocRoutes(routes ...*echo.Route) {
+	ownRoutes(r.echo, routes...)
+	for _, rt := range routes {
+		r.docRoutes[operationKey(rt.Method, rt.Path)] = true
+	}
+}
+
+func oauth2RedirectHandler(c echo.Context) error {
+	return c.HTML(http.StatusOK, OAuth2RedirectContent)
+}
+
+// mountAssets registers the route of renderer files for d only once
+func (r *Root) mountAssets(d *docMount) {
+	if r.ui.assets() == nil || d.assets || r.echo == nil {
+		return
+	}
+	d.assets = true
+	r.addDocRoutes(r.echo.GET(connectPath(d.path, AssetsPath, "*"), r.assetsHandler, d.m...))
+}
+
+func (r *Root) assetsHandler(c echo.Context) error {
+	return serveAssets(c, r.ui.assets())
+}
+
+// serveAssets serves the file of path parameter "*" in assets
+func serveAssets(c echo.Context, assets http.FileSystem) error {
+	name := c.Param("*")
+	if assets == nil || name == "" {
+		return echo.ErrNotFound
+	}
+	f, err := assets.Open(path.Clean("/" + name))
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		return echo.ErrNotFound
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(c.Response(), c.Request(), fi.Name(), fi.ModTime(), f)
+	return nil
+}
+
+// assetsURL returns the url of renderer files relative to the doc page
+func assetsURL(c echo.Context) string {
+	p := c.Request().URL.Path
+	if strings.HasSuffix(p, "/") {
+		return AssetsPath
+	}
+	return path.Base(p) + "/" + AssetsPath
 }
 
 type RawDefineDic map[string]RawDefine
@@ -33,49 +326,86 @@ type RawDefine struct {
 	Schema *JSONSchema
 }
 
-func (r *Root) docHandler(docPath string) echo.HandlerFunc {
-	t, err := template.New("swagger").Parse(SwaggerUIContent)
-	if err != nil {
-		panic(err)
+// docTemplate is the template of built-in doc pages
+var docTemplate = func() *template.Template {
+	t := template.New("doc")
+	for _, content := range []string{specLoaderContent, brandingContent, SwaggerUIContent, ReDocContent, RapiDocContent, IndexContent} {
+		template.Must(t.Parse(content))
 	}
+	return t
+}()
+
+func (r *Root) docHandler(docPath string) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		cdn := r.ui.CDN
-		if cdn == "" {
-			cdn = DefaultCDN
-		}
-		buf := new(bytes.Buffer)
 		params := map[string]interface{}{
-			"title":    r.spec.Info.Title,
-			"cdn":      cdn,
-			"specName": SpecName,
+			"title":   r.spec.Info.Title,
+			"docPath": docPath,
 		}
 		if !r.ui.DetachSpec {
 			spec, err := r.GetSpec(c, docPath)
 			if err != nil {
 				return c.String(http.StatusInternalServerError, err.Error())
 			}
+			spec.Host, spec.BasePath = r.host, r.basePath
 			b, err := json.Marshal(spec)
 			if err != nil {
 				return c.String(http.StatusInternalServerError, err.Error())
 			}
 			params["spec"] = string(b)
-			params["docPath"] = docPath
 			params["hideTop"] = true
 		} else {
 			params["hideTop"] = r.ui.HideTop
 		}
-		t.Execute(buf, params)
-		return c.HTMLBlob(http.StatusOK, buf.Bytes())
+		return r.ui.render(c, "", params)
 	}
 }
 
-func (r *RawDefineDic) getKey(v reflect.Value) (bool, string) {
+// render renders the doc page with params, name is the built-in template
+// to use, the template of renderer is used if it's empty.
+func (ui UISetting) render(c echo.Context, name string, params map[string]interface{}) error {
+	renderer, cdn := ui.renderer()
+	if ui.CDN != "" {
+		cdn = ui.CDN
+	} else if ui.assets() != nil {
+		cdn = assetsURL(c)
+	}
+	params["cdn"] = cdn
+	params["specName"] = SpecName
+	params["oauth2RedirectName"] = OAuth2RedirectName
+	if ui.OAuth != nil {
+		params["oauth"] = ui.OAuth
+	}
+	ui.branding(c, params)
+	config, err := ui.config()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	if urls, ok := params["urls"]; ok && config["urls"] == nil {
+		config["urls"] = urls
+	}
+	params["config"] = config
+
+	buf := new(bytes.Buffer)
+	if name == "" {
+		name = string(renderer)
+	}
+	if ui.Template != nil {
+		err = ui.Template.Execute(buf, params)
+	} else {
+		err = docTemplate.ExecuteTemplate(buf, name, params)
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+func (r *RawDefineDic) getKey(v reflect.Value, name string) (bool, string) {
 	for k, d := range *r {
 		if reflect.DeepEqual(d.Value.Interface(), v.Interface()) {
 			return true, k
 		}
 	}
-	name := v.Type().Name()
 	for k := range *r {
 		if name == k {
 			name += "_"
@@ -84,67 +414,207 @@ func (r *RawDefineDic) getKey(v reflect.Value) (bool, string) {
 	return false, name
 }
 
+func newGroup(name string, e *echo.Echo, echoGroup *echo.Group, defs *RawDefineDic) *group {
+	return &group{
+		echoGroup: echoGroup,
+		routers: routers{
+			echo: e,
+			defs: defs,
+		},
+		operation: Operation{
+			Responses: make(map[string]*Response),
+		},
+		tag: Tag{Name: name},
+	}
+}
+
+// tagName returns the name of tag which operations within the group use
+func (g *group) tagName() string {
+	if g.flatten && g.parent != nil {
+		return g.parent.tagName()
+	}
+	return g.tag.Name
+}
+
+// tagNames returns the tag names of the group and all nested groups
+func (g *group) tagNames() []string {
+	var names []string
+	if !g.flatten {
+		names = append(names, g.tag.Name)
+	}
+	for _, child := range g.groups {
+		names = append(names, child.tagNames()...)
+	}
+	return names
+}
+
+// ownedRoutes records routes registered by ApiRoots for each Echo, so that
+// routes of other ApiRoots on the same Echo are not handled as undocumented.
+// The routes of an Echo are released once all of its ApiRoots are built.
+var ownedRoutes = struct {
+	sync.RWMutex
+	m map[*echo.Echo]*routeRegistry
+}{m: make(map[*echo.Echo]*routeRegistry)}
+
+type routeRegistry struct {
+	// roots counts ApiRoots which are not built yet
+	roots  int
+	routes map[*echo.Route]bool
+}
+
+func registry(e *echo.Echo) *routeRegistry {
+	reg, ok := ownedRoutes.m[e]
+	if !ok {
+		reg = &routeRegistry{routes: make(map[*echo.Route]bool)}
+		ownedRoutes.m[e] = reg
+	}
+	return reg
+}
+
+func acquireRoutes(e *echo.Echo) {
+	ownedRoutes.Lock()
+	defer ownedRoutes.Unlock()
+	registry(e).roots++
+}
+
+func releaseRoutes(e *echo.Echo) {
+	ownedRoutes.Lock()
+	defer ownedRoutes.Unlock()
+	if reg, ok := ownedRoutes.m[e]; ok {
+		if reg.roots--; reg.roots <= 0 {
+			delete(ownedRoutes.m, e)
+		}
+	}
+}
+
+func ownRoutes(e *echo.Echo, routes ...*echo.Route) {
+	ownedRoutes.Lock()
+	defer ownedRoutes.Unlock()
+	reg := registry(e)
+	for _, rt := range routes {
+		reg.routes[rt] = true
+	}
+}
+
+func isOwnedRoute(e *echo.Echo, rt *echo.Route) bool {
+	ownedRoutes.RLock()
+	defer ownedRoutes.RUnlock()
+	reg, ok := ownedRoutes.m[e]
+	return ok && reg.routes[rt]
+}
+
 func (r *routers) appendRoute(route *echo.Route) *api {
 	opr := Operation{
 		Responses: make(map[string]*Response),
 	}
-	a := api{
+	a := &api{
 		route:     route,
 		defs:      r.defs,
 		operation: opr,
 	}
+	ownRoutes(r.echo, route)
 	r.apis = append(r.apis, a)
-	return &r.apis[len(r.apis)-1]
+	return a
+}
+
+func (r *routers) appendRoutes(routes []*echo.Route) multiApi {
+	m := make(multiApi, len(routes))
+	for i, route := range routes {
+		m[i] = r.appendRoute(route)
+	}
+	return m
 }
 
 func (g *api) addParams(p interface{}, in ParamInType, name, desc string, required, nest bool) Api {
+	g.operation.addParams(p, in, name, desc, required, nest)
+	return g
+}
+
+func (o *Operation) addParams(p interface{}, in ParamInType, name, desc string, required, nest bool) {
 	if !isValidParam(reflect.TypeOf(p), nest, false) {
 		panic("echoswagger: invalid " + string(in) + " param")
 	}
 	rt := indirectType(p)
 	st, sf := toSwaggerType(rt)
 	if st == "object" && sf == "object" {
-		g.operation.handleParamStruct(rt, in)
+		o.handleParamStruct(rt, in)
 	} else {
-		name = g.operation.rename(name)
-		pm := &Parameter{
-			Name:        name,
-			In:          string(in),
-			Description: desc,
-			Required:    required,
-			Type:        st,
-		}
-		if st == "array" {
-			pm.Items = Items{}.generate(rt.Elem())
-			pm.CollectionFormat = "multi"
-		} else {
-			pm.Format = sf
-		}
-		g.operation.Parameters = append(g.operation.Parameters, pm)
+		name = o.rename(name)
+		o.Parameters = append(o.Parameters, genParam(p, in, name, desc, required))
 	}
-	return g
 }
 
 func (g *api) addBodyParams(p interface{}, name, desc string, required bool) Api {
-	if !isValidSchema(reflect.TypeOf(p), false) {
-		panic("echoswagger: invalid body parameter")
-	}
 	for _, param := range g.operation.Parameters {
 		if param.In == string(ParamInBody) {
 			panic("echoswagger: multiple body parameters are not allowed")
 		}
 	}
+	g.operation.Parameters = append(g.operation.Parameters, g.defs.genBodyParam(p, name, desc, required))
+	return g
+}
 
-	rv := indirectValue(p)
+// genParam generates a non-body parameter of basic or array type
+func genParam(p interface{}, in ParamInType, name, desc string, required bool) *Parameter {
+	if !isValidParam(reflect.TypeOf(p), false, false) {
+		panic("echoswagger: invalid " + string(in) + " param")
+	}
+	rt := indirectType(p)
+	st, sf := toSwaggerType(rt)
+	if st == "object" {
+		panic("echoswagger: invalid " + string(in) + " param")
+	}
 	pm := &Parameter{
+		Name:        name,
+		In:          string(in),
+		Description: desc,
+		Required:    required,
+		Type:        st,
+	}
+	if st == "array" {
+		pm.Items = Items{}.generate(rt.Elem())
+		pm.CollectionFormat = "multi"
+	} else {
+		pm.Format = sf
+	}
+	return pm
+}
+
+func (r *RawDefineDic) genBodyParam(p interface{}, name, desc string, required bool) *Parameter {
+	if !isValidSchema(reflect.TypeOf(p), false) {
+		panic("echoswagger: invalid body parameter")
+	}
+	rv := indirectValue(p)
+	return &Parameter{
 		Name:        name,
 		In:          string(ParamInBody),
 		Description: desc,
 		Required:    required,
-		Schema:      g.defs.genSchema(rv),
+		Schema:      r.genSchema(rv),
 	}
-	g.operation.Parameters = append(g.operation.Parameters, pm)
-	return g
+}
+
+func (r *RawDefineDic) genResponse(desc string, schema interface{}, header interface{}) *Response {
+	resp := &Response{
+		Description: desc,
+	}
+
+	st := reflect.TypeOf(schema)
+	if st != nil {
+		if !isValidSchema(st, false) {
+			panic("echoswagger: invalid response schema")
+		}
+		resp.Schema = r.genSchema(reflect.ValueOf(schema))
+	}
+
+	ht := reflect.TypeOf(header)
+	if ht != nil {
+		if !isValidParam(reflect.TypeOf(header), true, false) {
+			panic("echoswagger: invalid response header")
+		}
+		resp.Headers = genHeader(reflect.ValueOf(header))
+	}
+	return resp
 }
 
 func (o Operation) rename(s string) string {
@@ -169,3 +639,28 @@ func (o *Operation) handleParamStruct(rt reflect.Type, in ParamInType) {
 		}
 	}
 }
+
+// inherit merges parameters, responses and content types of d into o,
+// the values of o take precedence
+func (o *Operation) inherit(d *Operation) {
+LoopParams:
+	for _, dp := range d.Parameters {
+		for _, p := range o.Parameters {
+			if p.Name == dp.Name && p.In == dp.In && p.Ref == dp.Ref {
+				continue LoopParams
+			}
+		}
+		o.Parameters = append(o.Parameters, dp)
+	}
+	for code, resp := range d.Responses {
+		if _, ok := o.Responses[code]; !ok {
+			o.Responses[code] = resp
+		}
+	}
+	if len(o.Consumes) == 0 {
+		o.Consumes = d.Consumes
+	}
+	if len(o.Produces) == 0 {
+		o.Produces = d.Produces
+	}
+}
@@ -0,0 +1,295 @@
+package echoswagger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// OperationContextKey is the key of the Operation stored in echo.Context
+// by ApiRoot.InjectOperation.
+const OperationContextKey = "echoswagger.operation"
+
+// SecurityContextKey is the key of the effective Security requirements
+// stored in echo.Context by ApiRoot.InjectOperation.
+const SecurityContextKey = "echoswagger.security"
+
+func operationKey(method, path string) string {
+	return method + " " + path
+}
+
+// lookup returns the operation for the matched route of c,
+// it returns nil if the route is not documented, and the error of
+// spec generation if spec is invalid.
+func (r *Root) lookup(c echo.Context) (*Operation, error) {
+	if o := OperationFromContext(c); o != nil {
+		return o, nil
+	}
+	if err := r.build(c); err != nil {
+		return nil, err
+	}
+	return r.operations[operationKey(c.Request().Method, c.Path())], nil
+}
+
+func (r *Root) InjectOperation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if o, _ := r.lookup(c); o != nil {
+				c.Set(OperationContextKey, o)
+				c.Set(SecurityContextKey, r.effectiveSecurity(o))
+			}
+			return next(c)
+		}
+	}
+}
+
+// OperationFromContext returns the Operation stored by ApiRoot.InjectOperation,
+// it returns nil if the route is not documented. The Operation is shared by
+// requests and must not be modified.
+func OperationFromContext(c echo.Context) *Operation {
+	o, _ := c.Get(OperationContextKey).(*Operation)
+	return o
+}
+
+// SecurityFromContext returns the Security requirements of the operation
+// stored by ApiRoot.InjectOperation, which fall back to the global Security
+// set by ApiRoot.SetSecurity. It returns nil if the route is not documented,
+// and an empty slice if the operation is public.
+func SecurityFromContext(c echo.Context) []map[string][]string {
+	s, _ := c.Get(SecurityContextKey).([]map[string][]string)
+	return s
+}
+
+// effectiveSecurity returns Security of o, or the global Security if o has none
+func (r *Root) effectiveSecurity(o *Operation) []map[string][]string {
+	if o.Security != nil {
+		return o.Security
+	}
+	if r.spec.Security == nil {
+		return make([]map[string][]string, 0)
+	}
+	return r.spec.Security
+}
+
+func (r *Root) ValidateRequest() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			o, _ := r.lookup(c)
+			if o == nil {
+				return next(c)
+			}
+			errs, err := r.validateRequest(c, o)
+			if err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, &ValidationErrors{
+					Message: "invalid request",
+					Errors:  errs,
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+func (r *Root) validateRequest(c echo.Context, o *Operation) ([]*ValidationError, error) {
+	var errs []*ValidationError
+	for _, p := range o.Parameters {
+		if p.Ref != "" {
+			p = r.spec.Parameters[p.Ref[len(ParamPrefix):]]
+			if p == nil {
+				continue
+			}
+		}
+		if p.In == string(ParamInBody) {
+			es, err := r.validateBody(c, p)
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, es...)
+			continue
+		}
+
+		values, err := paramValues(c, p)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 || values[0] == "" && !p.AllowEmptyValue {
+			if p.Required {
+				errs = append(errs, &ValidationError{In: p.In, Name: p.Name, Message: "is required"})
+			}
+			continue
+		}
+		for _, m := range p.constraints().validateValues(values) {
+			errs = append(errs, &ValidationError{In: p.In, Name: p.Name, Message: m})
+		}
+	}
+	return errs, nil
+}
+
+// paramValues returns values of a non-body parameter from request
+func paramValues(c echo.Context, p *Parameter) ([]string, error) {
+	switch ParamInType(p.In) {
+	case ParamInPath:
+		for _, n := range c.ParamNames() {
+			if n == p.Name {
+				return []string{c.Param(n)}, nil
+			}
+		}
+	case ParamInQuery:
+		return c.QueryParams()[p.Name], nil
+	case ParamInHeader:
+		return c.Request().Header[textproto.CanonicalMIMEHeaderKey(p.Name)], nil
+	case ParamInFormData:
+		if p.Type == "file" {
+			if _, err := c.FormFile(p.Name); err != nil {
+				return nil, nil
+			}
+			return []string{p.Name}, nil
+		}
+		form, err := c.FormParams()
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return form[p.Name], nil
+	}
+	return nil, nil
+}
+
+// validateBody validates JSON request body and restores it for the handler
+func (r *Root) validateBody(c echo.Context, p *Parameter) ([]*ValidationError, error) {
+	req := c.Request()
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if p.Required {
+			return []*ValidationError{{In: p.In, Name: p.Name, Message: "is required"}}, nil
+		}
+		return nil, nil
+	}
+	ctype := req.Header.Get(echo.HeaderContentType)
+	if ctype != "" && !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+		return nil, nil
+	}
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return []*ValidationError{{In: p.In, Name: p.Name, Message: "should be valid JSON"}}, nil
+	}
+	sv := &schemaValidator{in: p.In, defs: r.spec.Definitions}
+	return sv.validate(v, p.Schema, p.Name), nil
+}
+
+func (r *Root) ValidateResponse(report func(c echo.Context, err error)) echo.MiddlewareFunc {
+	if report == nil {
+		report = func(c echo.Context, err error) {
+			c.Logger().Warn(err)
+		}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			o, _ := r.lookup(c)
+			if o == nil {
+				return next(c)
+			}
+			res := c.Response()
+			w := &bodyRecorder{ResponseWriter: res.Writer}
+			res.Writer = w
+			err := next(c)
+			if err != nil && !res.Committed {
+				c.Error(err)
+			}
+			res.Writer = w.ResponseWriter
+
+			if errs := r.validateResponse(res, w.body.Bytes(), o); len(errs) > 0 {
+				report(c, &ValidationErrors{
+					Message: "invalid response",
+					Errors:  errs,
+				})
+			}
+			return err
+		}
+	}
+}
+
+// bodyRecorder records the response body while writing it
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming responses
+func (w *bodyRecorder) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// Hijack implements http.Hijacker for websocket connections
+func (w *bodyRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (r *Root) validateResponse(res *echo.Response, body []byte, o *Operation) []*ValidationError {
+	code := strconv.Itoa(res.Status)
+	resp, ok := o.Responses[code]
+	if !ok {
+		resp, ok = o.Responses["default"]
+	}
+	if !ok {
+		return []*ValidationError{{In: "response", Name: "status", Message: "undeclared status code " + code}}
+	}
+	if resp.Ref != "" {
+		resp = r.spec.Responses[resp.Ref[len(RespPrefix):]]
+		if resp == nil {
+			return nil
+		}
+	}
+
+	var errs []*ValidationError
+	for name, h := range resp.Headers {
+		values := res.Header()[textproto.CanonicalMIMEHeaderKey(name)]
+		if len(values) == 0 {
+			errs = append(errs, &ValidationError{In: "header", Name: name, Message: "is missing"})
+			continue
+		}
+		for _, m := range h.constraints().validateValues(values) {
+			errs = append(errs, &ValidationError{In: "header", Name: name, Message: m})
+		}
+	}
+
+	ctype := res.Header().Get(echo.HeaderContentType)
+	if resp.Schema == nil || len(bytes.TrimSpace(body)) == 0 ||
+		!strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+		return errs
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return append(errs, &ValidationError{In: "response", Name: "body", Message: "should be valid JSON"})
+	}
+	sv := &schemaValidator{in: "response", defs: r.spec.Definitions, strict: true}
+	return append(errs, sv.validate(v, resp.Schema, "body")...)
+}
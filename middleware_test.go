@@ -0,0 +1,308 @@
+package echoswagger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRequest(t *testing.T) {
+	type Pet struct {
+		Name string   `json:"name" swagger:"minLen(2),required"`
+		Age  int      `json:"age" swagger:"min(0),max(30)"`
+		Tags []string `json:"tags" swagger:"enum(cat|dog)"`
+	}
+	type Query struct {
+		Limit int     `query:"limit" swagger:"min(1),max(100)"`
+		Sort  string  `query:"sort" swagger:"enum(asc|desc)"`
+		Ratio float32 `query:"ratio" swagger:"enum(1.1|2.2)"`
+	}
+
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	var body string
+	h := func(c echo.Context) error {
+		b, err := ioutil.ReadAll(c.Request().Body)
+		body = string(b)
+		if err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	}
+	r.GET("/pets", h, r.ValidateRequest()).AddParamQueryNested(&Query{})
+	r.PUT("/pets/:id", h, r.ValidateRequest()).
+		AddParamPath(int64(0), "id", "").
+		AddParamBody(&Pet{}, "body", "", true)
+	r.GET("/raw", h, r.ValidateRequest())
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+		errors []*ValidationError
+	}{
+		{
+			name:   "ValidQuery",
+			method: echo.GET,
+			target: "/pets?limit=10&sort=asc&ratio=1.1",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "InvalidQuery",
+			method: echo.GET,
+			target: "/pets?limit=abc&sort=up",
+			code:   http.StatusBadRequest,
+			errors: []*ValidationError{
+				{In: "query", Name: "limit", Message: "should be an integer"},
+				{In: "query", Name: "sort", Message: "should be one of [asc desc]"},
+			},
+		},
+		{
+			name:   "ValidBody",
+			method: echo.PUT,
+			target: "/pets/1",
+			body:   `{"name":"Tom","age":3,"tags":["cat"]}`,
+			code:   http.StatusOK,
+		},
+		{
+			name:   "InvalidBody",
+			method: echo.PUT,
+			target: "/pets/1",
+			body:   `{"age":31,"tags":["fish"]}`,
+			code:   http.StatusBadRequest,
+			errors: []*ValidationError{
+				{In: "body", Name: "body.name", Message: "is required"},
+				{In: "body", Name: "body.age", Message: "should be less than 30"},
+				{In: "body", Name: "body.tags[0]", Message: "should be one of [cat dog]"},
+			},
+		},
+		{
+			name:   "InvalidPath",
+			method: echo.PUT,
+			target: "/pets/x",
+			body:   `{"name":"Tom"}`,
+			code:   http.StatusBadRequest,
+			errors: []*ValidationError{
+				{In: "path", Name: "id", Message: "should be an integer"},
+			},
+		},
+		{
+			name:   "MissingBody",
+			method: echo.PUT,
+			target: "/pets/1",
+			code:   http.StatusBadRequest,
+			errors: []*ValidationError{
+				{In: "body", Name: "body", Message: "is required"},
+			},
+		},
+		{
+			name:   "NoParams",
+			method: echo.GET,
+			target: "/raw?any=1",
+			code:   http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = ""
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code, rec.Body.String())
+			if tt.code == http.StatusOK {
+				assert.Equal(t, tt.body, body)
+				return
+			}
+			var v ValidationErrors
+			if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v)) {
+				assert.Equal(t, "invalid request", v.Message)
+				assert.ElementsMatch(t, tt.errors, v.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	type Pet struct {
+		Name string `json:"name"`
+		Age  int    `json:"age" swagger:"max(30)"`
+	}
+	type RateLimit struct {
+		Remaining int `json:"X-Rate-Remaining"`
+	}
+
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	var reported error
+	m := r.ValidateResponse(func(c echo.Context, err error) {
+		reported = err
+	})
+	var handler echo.HandlerFunc
+	h := func(c echo.Context) error {
+		return handler(c)
+	}
+	r.GET("/pets", h, m).
+		AddResponse(http.StatusOK, "pet", &Pet{}, &RateLimit{}).
+		AddResponse(http.StatusNotFound, "not found", nil, nil)
+	r.GET("/raw", h, m)
+	var returned error
+	outer := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			returned = next(c)
+			return returned
+		}
+	}
+	r.GET("/outer", func(c echo.Context) error {
+		return echo.ErrNotFound
+	}, outer, m).AddResponse(http.StatusNotFound, "not found", nil, nil)
+
+	tests := []struct {
+		name    string
+		target  string
+		handler echo.HandlerFunc
+		code    int
+		errors  []*ValidationError
+	}{
+		{
+			name:   "Valid",
+			target: "/pets",
+			handler: func(c echo.Context) error {
+				c.Response().Header().Set("X-Rate-Remaining", "10")
+				return c.JSON(http.StatusOK, map[string]interface{}{"name": "Tom", "age": 3})
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:   "InvalidBody",
+			target: "/pets",
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"name": 1, "age": 31, "owner": "Jim"})
+			},
+			code: http.StatusOK,
+			errors: []*ValidationError{
+				{In: "header", Name: "X-Rate-Remaining", Message: "is missing"},
+				{In: "response", Name: "body.name", Message: "should be a string"},
+				{In: "response", Name: "body.age", Message: "should be less than 30"},
+				{In: "response", Name: "body.owner", Message: "is not declared"},
+			},
+		},
+		{
+			name:   "ErrorDeclared",
+			target: "/pets",
+			handler: func(c echo.Context) error {
+				return echo.ErrNotFound
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:   "ErrorUndeclared",
+			target: "/pets",
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusConflict)
+			},
+			code: http.StatusConflict,
+			errors: []*ValidationError{
+				{In: "response", Name: "status", Message: "undeclared status code 409"},
+			},
+		},
+		{
+			name:   "Default",
+			target: "/raw",
+			handler: func(c echo.Context) error {
+				return c.String(http.StatusTeapot, "tea")
+			},
+			code: http.StatusTeapot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported = nil
+			handler = tt.handler
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, tt.target, nil))
+			assert.Equal(t, tt.code, rec.Code)
+			if len(tt.errors) == 0 {
+				assert.NoError(t, reported)
+				return
+			}
+			if assert.IsType(t, &ValidationErrors{}, reported) {
+				assert.Equal(t, "invalid response", reported.(*ValidationErrors).Message)
+				assert.ElementsMatch(t, tt.errors, reported.(*ValidationErrors).Errors)
+			}
+		})
+	}
+
+	t.Run("ReturnError", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/outer", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, echo.ErrNotFound, returned)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		handler = func(c echo.Context) error {
+			c.Response().WriteHeader(http.StatusTeapot)
+			c.Response().Write([]byte("data: tea\n\n"))
+			c.Response().Flush()
+			return nil
+		}
+		rec := httptest.NewRecorder()
+		assert.NotPanics(t, func() {
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/raw", nil))
+		})
+		assert.True(t, rec.Flushed)
+	})
+}
+
+func TestInjectOperation(t *testing.T) {
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	r.AddSecurityAPIKey("JWT", "JWT Token", SecurityInHeader)
+	r.AddSecurityBasic("Basic", "Basic Auth")
+	r.SetSecurity("Basic")
+	e.Use(r.InjectOperation())
+
+	var o *Operation
+	var security []map[string][]string
+	h := func(c echo.Context) error {
+		o = OperationFromContext(c)
+		security = SecurityFromContext(c)
+		return nil
+	}
+	g := r.Group("Pets", "/pets").SetSecurity("JWT")
+	g.GET("/:id", h).SetOperationId("getPet").SetHidden()
+	r.GET("/global", h)
+	r.GET("/public", h).SetNoSecurity()
+	e.GET("/raw", h)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/pets/1", nil))
+	if assert.NotNil(t, o) {
+		assert.Equal(t, "getPet", o.OperationID)
+		assert.Equal(t, []string{"Pets"}, o.Tags)
+		assert.Equal(t, []map[string][]string{{"JWT": {}}}, o.Security)
+	}
+	assert.Equal(t, []map[string][]string{{"JWT": {}}}, security)
+
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/global", nil))
+	if assert.NotNil(t, o) {
+		assert.Nil(t, o.Security)
+	}
+	assert.Equal(t, []map[string][]string{{"Basic": {}}}, security)
+
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/public", nil))
+	assert.Equal(t, []map[string][]string{}, security)
+
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/raw", nil))
+	assert.Nil(t, o)
+	assert.Nil(t, security)
+}
@@ -16,7 +16,9 @@ type (
 		Parameters          map[string]*Parameter          `json:"parameters,omitempty"`
 		Responses           map[string]*Response           `json:"responses,omitempty"`
 		SecurityDefinitions map[string]*SecurityDefinition `json:"securityDefinitions,omitempty"`
+		Security            []map[string][]string          `json:"security,omitempty"`
 		Tags                []*Tag                         `json:"tags,omitempty"`
+		TagGroups           []*TagGroup                    `json:"x-tagGroups,omitempty"`
 		ExternalDocs        *ExternalDocs                  `json:"externalDocs,omitempty"`
 	}
 
@@ -111,6 +113,9 @@ type (
 
 	// Parameter describes a single operation parameter.
 	Parameter struct {
+		// Ref references a global API parameter.
+		// This field is exclusive with the other fields of Parameter.
+		Ref string `json:"$ref,omitempty"`
 		// Name of the parameter. Parameter names are case sensitive.
 		Name string `json:"name"`
 		// In is the location of the parameter.
@@ -296,6 +301,14 @@ type (
 		Extensions map[string]interface{} `json:"-"`
 	}
 
+	// TagGroup groups tags hierarchically with the `x-tagGroups` extension.
+	TagGroup struct {
+		// Name of the tag group.
+		Name string `json:"name"`
+		// Tags is a list of tag names within the tag group.
+		Tags []string `json:"tags"`
+	}
+
 	JSONSchema struct {
 		Schema string `json:"$schema,omitempty"`
 		// Core schema
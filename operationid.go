@@ -0,0 +1,101 @@
+package echoswagger
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/labstack/echo"
+)
+
+// OperationIDFunc generates operationId for an operation which has no
+// operationId set by Api.SetOperationId. Empty result means no operationId.
+type OperationIDFunc func(route *echo.Route, operation *Operation) string
+
+// OperationIDFromHandler generates operationId from the name of handler function,
+// e.g. "main.(*PetController).FindByStatus-fm" → "findByStatus".
+// Anonymous functions generate no operationId.
+func OperationIDFromHandler(route *echo.Route, operation *Operation) string {
+	name := strings.TrimSuffix(route.Name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || isAnonymousFunc(name) {
+		return ""
+	}
+	return toCamelCase(name)
+}
+
+// OperationIDFromPath generates operationId from method and path,
+// e.g. "GET /pets/:petId" → "getPetsByPetId".
+func OperationIDFromPath(route *echo.Route, operation *Operation) string {
+	words := []string{strings.ToLower(route.Method)}
+	for _, s := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(s, ":") {
+			words = append(words, "by", s[1:])
+		} else if s != "" && s != "*" {
+			words = append(words, s)
+		}
+	}
+	return toCamelCase(words...)
+}
+
+// OperationIDFromTag generates operationId from verb and the first tag,
+// e.g. "GET /pets" with tag "pet" → "listPet", "GET /pets/:id" → "getPet".
+// Operations without tags fall back to OperationIDFromPath.
+func OperationIDFromTag(route *echo.Route, operation *Operation) string {
+	if len(operation.Tags) == 0 {
+		return OperationIDFromPath(route, operation)
+	}
+	var verb string
+	switch route.Method {
+	case echo.GET:
+		if strings.HasPrefix(route.Path[strings.LastIndex(route.Path, "/")+1:], ":") {
+			verb = "get"
+		} else {
+			verb = "list"
+		}
+	case echo.POST:
+		verb = "create"
+	case echo.PUT, echo.PATCH:
+		verb = "update"
+	default:
+		verb = strings.ToLower(route.Method)
+	}
+	return toCamelCase(verb, operation.Tags[0])
+}
+
+// isAnonymousFunc reports whether name is generated for anonymous function,
+// eg. "func1", or "1" of nested anonymous function "main.main.func1.1"
+func isAnonymousFunc(name string) bool {
+	digits := strings.TrimPrefix(name, "func")
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// toCamelCase joins words in lower camel case, non-alphanumeric
+// characters are treated as separators
+func toCamelCase(words ...string) string {
+	var b strings.Builder
+	for _, w := range words {
+		parts := strings.FieldsFunc(w, func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+		})
+		for _, p := range parts {
+			rs := []rune(p)
+			if b.Len() == 0 {
+				rs[0] = unicode.ToLower(rs[0])
+			} else {
+				rs[0] = unicode.ToUpper(rs[0])
+			}
+			b.WriteString(string(rs))
+		}
+	}
+	return b.String()
+}
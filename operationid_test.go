@@ -0,0 +1,90 @@
+package echoswagger
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type petController struct{}
+
+func (petController) FindByStatus(c echo.Context) error {
+	return nil
+}
+
+func TestOperationIDFuncs(t *testing.T) {
+	tests := []struct {
+		route                    echo.Route
+		tags                     []string
+		handler, path, tag, name string
+	}{
+		{
+			route:   echo.Route{Method: echo.GET, Path: "/pets/:petId", Name: "main.(*PetController).FindById-fm"},
+			tags:    []string{"pet"},
+			handler: "findById",
+			path:    "getPetsByPetId",
+			tag:     "getPet",
+			name:    "Method value",
+		},
+		{
+			route:   echo.Route{Method: echo.GET, Path: "/pets", Name: "main.main.func1"},
+			tags:    []string{"pet"},
+			handler: "",
+			path:    "getPets",
+			tag:     "listPet",
+			name:    "Anonymous function",
+		},
+		{
+			route:   echo.Route{Method: echo.GET, Path: "/pets", Name: "main.main.func1.1"},
+			tags:    []string{"pet"},
+			handler: "",
+			path:    "getPets",
+			tag:     "listPet",
+			name:    "Nested anonymous function",
+		},
+		{
+			route:   echo.Route{Method: echo.POST, Path: "/store/order-items", Name: "github.com/a/b.CreateOrder"},
+			handler: "createOrder",
+			path:    "postStoreOrderItems",
+			tag:     "postStoreOrderItems",
+			name:    "No tags",
+		},
+		{
+			route:   echo.Route{Method: echo.DELETE, Path: "/users/:id", Name: "main.deleteUser"},
+			tags:    []string{"user admin"},
+			handler: "deleteUser",
+			path:    "deleteUsersById",
+			tag:     "deleteUserAdmin",
+			name:    "Delete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Operation{Tags: tt.tags}
+			assert.Equal(t, tt.handler, OperationIDFromHandler(&tt.route, o))
+			assert.Equal(t, tt.path, OperationIDFromPath(&tt.route, o))
+			assert.Equal(t, tt.tag, OperationIDFromTag(&tt.route, o))
+		})
+	}
+}
+
+func TestSetOperationIDFunc(t *testing.T) {
+	r := prepareApiRoot()
+	r.SetOperationIDFunc(OperationIDFromHandler)
+	g := r.Group("Pets", "/pets")
+	g.GET("/findByStatus", petController{}.FindByStatus)
+	g.GET("/findByTags", petController{}.FindByStatus)
+	g.POST("", petController{}.FindByStatus).SetOperationId("createPet")
+	r.GET("/status", petController{}.FindByStatus).SetOperationId("findByStatus")
+
+	c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+	if assert.NoError(t, r.(*Root).genSpec(c)) {
+		s := r.(*Root).spec
+		assert.Equal(t, "getPetsFindByStatus", s.Paths["/pets/findByStatus"].(*Path).Get.OperationID)
+		assert.Equal(t, "getPetsFindByTags", s.Paths["/pets/findByTags"].(*Path).Get.OperationID)
+		assert.Equal(t, "createPet", s.Paths["/pets"].(*Path).Post.OperationID)
+		assert.Equal(t, "findByStatus", s.Paths["/status"].(*Path).Get.OperationID)
+	}
+}
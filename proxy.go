@@ -0,0 +1,105 @@
+package echoswagger
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// forwarded is the location of request resolved from proxy headers
+type forwarded struct {
+	host   string
+	proto  string
+	prefix string
+}
+
+// DefaultTrustedProxies are trusted by ApiRoot.SetForwarded if no proxy
+// is given, which are loopback and private networks.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16",
+	"::1/128", "fc00::/7",
+}
+
+// parseTrustedProxies parses IPs and CIDRs of trusted proxies,
+// DefaultTrustedProxies are used if proxies is empty.
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	if len(proxies) == 0 {
+		proxies = DefaultTrustedProxies
+	}
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				panic("echoswagger: invalid trusted proxy: " + p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			panic("echoswagger: invalid trusted proxy: " + p)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// isTrustedProxy reports whether the remote address of req is a trusted proxy
+func isTrustedProxy(req *http.Request, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseForwarded resolves the location of req from "Forwarded" (RFC 7239)
+// and "X-Forwarded-*" headers, "Forwarded" takes precedence.
+func parseForwarded(req *http.Request) forwarded {
+	f := forwarded{
+		host:   firstValue(req.Header.Get("X-Forwarded-Host")),
+		proto:  firstValue(req.Header.Get("X-Forwarded-Proto")),
+		prefix: firstValue(req.Header.Get("X-Forwarded-Prefix")),
+	}
+	if v := req.Header.Get("Forwarded"); v != "" {
+		// the first element is added by the proxy closest to the client
+		for _, pair := range strings.Split(firstValue(v), ";") {
+			kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			value := strings.Trim(kv[1], `"`)
+			switch strings.ToLower(kv[0]) {
+			case "host":
+				f.host = value
+			case "proto":
+				f.proto = strings.ToLower(value)
+			}
+		}
+	}
+	if f.prefix != "" {
+		f.prefix = removeTrailingSlash(connectPath(f.prefix))
+	}
+	return f
+}
+
+func firstValue(v string) string {
+	if i := strings.Index(v, ","); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v)
+}
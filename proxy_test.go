@@ -0,0 +1,133 @@
+package echoswagger
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(r ApiRoot)
+		remote   string
+		header   map[string]string
+		host     string
+		basePath string
+		schemes  []string
+	}{
+		{
+			name:     "Default",
+			setup:    func(r ApiRoot) {},
+			header:   map[string]string{"X-Forwarded-Host": "api.example.com"},
+			host:     "internal:8080",
+			basePath: "",
+		},
+		{
+			name: "Explicit",
+			setup: func(r ApiRoot) {
+				r.SetHost("api.example.com").SetBasePath("api/").SetForwarded()
+			},
+			header:   map[string]string{"X-Forwarded-Host": "other.example.com"},
+			host:     "api.example.com",
+			basePath: "/api/",
+		},
+		{
+			name: "XForwarded",
+			setup: func(r ApiRoot) {
+				r.SetForwarded()
+			},
+			remote: "127.0.0.1:4567",
+			header: map[string]string{
+				"X-Forwarded-Host":   "api.example.com, proxy.local",
+				"X-Forwarded-Proto":  "https",
+				"X-Forwarded-Prefix": "/pets/",
+			},
+			host:     "api.example.com",
+			basePath: "/pets",
+			schemes:  []string{"https"},
+		},
+		{
+			name: "Forwarded",
+			setup: func(r ApiRoot) {
+				r.SetForwarded("10.0.0.0/8", "192.0.2.1")
+			},
+			remote: "10.1.2.3:4567",
+			header: map[string]string{
+				"Forwarded":        `for=198.51.100.17;Host="api.example.com";proto=HTTPS, for=10.0.0.1;host=proxy.local`,
+				"X-Forwarded-Host": "other.example.com",
+			},
+			host:    "api.example.com",
+			schemes: []string{"https"},
+		},
+		{
+			name: "SchemeSet",
+			setup: func(r ApiRoot) {
+				r.SetScheme("http", "https").SetForwarded("192.0.2.1")
+			},
+			header:  map[string]string{"X-Forwarded-Proto": "https"},
+			host:    "internal:8080",
+			schemes: []string{"http", "https"},
+		},
+		{
+			name: "DefaultUntrusted",
+			setup: func(r ApiRoot) {
+				r.SetForwarded()
+			},
+			header: map[string]string{"X-Forwarded-Host": "evil.example.com"},
+			host:   "internal:8080",
+		},
+		{
+			name: "Untrusted",
+			setup: func(r ApiRoot) {
+				r.SetForwarded("10.0.0.0/8")
+			},
+			header: map[string]string{
+				"X-Forwarded-Host":   "api.example.com",
+				"X-Forwarded-Prefix": "/pets",
+			},
+			host: "internal:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			r := New(e, "doc/", nil)
+			tt.setup(r)
+			req := httptest.NewRequest(echo.GET, "/doc/swagger.json", nil)
+			req.Host = "internal:8080"
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tt.remote != "" {
+				req.RemoteAddr = tt.remote
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			var spec Swagger
+			if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec)) {
+				assert.Equal(t, tt.host, spec.Host)
+				assert.Equal(t, tt.basePath, spec.BasePath)
+				assert.Equal(t, tt.schemes, spec.Schemes)
+			}
+		})
+	}
+
+	assert.Panics(t, func() {
+		New(echo.New(), "doc/", nil).SetForwarded("proxy.local")
+	})
+
+	t.Run("DocPage", func(t *testing.T) {
+		e := echo.New()
+		New(e, "doc/", nil).SetHost("api.example.com").SetBasePath("/api")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), "api.example.com")
+		assert.Contains(t, rec.Body.String(), "if (spec && !spec.host)")
+	})
+}
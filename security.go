@@ -1,6 +1,14 @@
 package echoswagger
 
-import "errors"
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo"
+)
 
 type SecurityType string
 
@@ -26,6 +34,28 @@ const (
 	OAuth2FlowAccessCode  OAuth2FlowType = "accessCode"
 )
 
+// OAuth2Flow describes a flow of oauth2 security for AddSecurityOAuth2Flows.
+type OAuth2Flow struct {
+	Flow             OAuth2FlowType    `json:"-"`
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// isBearer reports whether d is added by AddSecurityBearer or AddSecurityOpenIDConnect
+func (d *SecurityDefinition) isBearer() bool {
+	if d.Extensions["x-scheme"] == "bearer" {
+		return true
+	}
+	_, ok := d.Extensions["x-openIdConnectUrl"]
+	return ok
+}
+
+// isMutualTLS reports whether d is added by AddSecurityMutualTLS
+func (d *SecurityDefinition) isMutualTLS() bool {
+	return d.Extensions["x-scheme"] == "mutualTLS"
+}
+
 func (r *Root) checkSecurity(name string) bool {
 	if name == "" {
 		return false
@@ -61,10 +91,14 @@ func setSecurityWithScope(security []map[string][]string, s ...map[string][]stri
 
 func (o *Operation) addSecurity(defs map[string]*SecurityDefinition, security []map[string][]string) error {
 	for _, scy := range security {
-		for k := range scy {
-			if _, ok := defs[k]; !ok {
+		for k, scopes := range scy {
+			d, ok := defs[k]
+			if !ok {
 				return errors.New("echoswagger: not found SecurityDefinition with name: " + k)
 			}
+			if len(scopes) > 0 && d.Type != string(SecurityOAuth2) {
+				return errors.New("echoswagger: scopes are only allowed for oauth2 SecurityDefinition: " + k)
+			}
 		}
 		if containsMap(o.Security, scy) {
 			continue
@@ -73,3 +107,170 @@ func (o *Operation) addSecurity(defs map[string]*SecurityDefinition, security []
 	}
 	return nil
 }
+
+// SecurityVerifiers verify credentials of requests for ApiRoot.EnforceSecurity,
+// name is the name of SecurityDefinition. Returning an error aborts the request
+// with the error, and a nil verifier never authenticates.
+type SecurityVerifiers struct {
+	// Basic verifies username and password of basic security.
+	Basic func(c echo.Context, name, username, password string) (bool, error)
+	// APIKey verifies the key of apiKey security.
+	APIKey func(c echo.Context, name, key string) (bool, error)
+	// Bearer verifies the bearer token of bearer and OpenID Connect security.
+	Bearer func(c echo.Context, name, token string) (bool, error)
+	// OAuth2 verifies the bearer token of oauth2 security and returns granted scopes.
+	OAuth2 func(c echo.Context, name, token string) (ok bool, scopes []string, err error)
+	// MutualTLS verifies the client certificates of mutual TLS security,
+	// the first certificate is the leaf.
+	MutualTLS func(c echo.Context, name string, certs []*x509.Certificate) (bool, error)
+}
+
+func (r *Root) EnforceSecurity(v SecurityVerifiers) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			o, err := r.lookup(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+			}
+			var security []map[string][]string
+			if o != nil {
+				security = r.effectiveSecurity(o)
+			} else if !r.docRoutes[operationKey(c.Request().Method, c.Path())] {
+				security = r.spec.Security
+			}
+			if len(security) == 0 {
+				return next(c)
+			}
+			forbidden, basic := false, false
+			for _, scy := range security {
+				ok, authenticated, err := r.verifySecurity(c, v, scy)
+				if err != nil {
+					return err
+				}
+				if ok {
+					return next(c)
+				}
+				forbidden = forbidden || authenticated
+				for name := range scy {
+					if d := r.spec.SecurityDefinitions[name]; d != nil && d.Type == string(SecurityBasic) {
+						basic = true
+					}
+				}
+			}
+			if forbidden {
+				return echo.ErrForbidden
+			}
+			if basic {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Basic realm=Restricted")
+			}
+			return echo.ErrUnauthorized
+		}
+	}
+}
+
+// verifySecurity verifies all securities of scy, authenticated reports
+// whether credentials are valid but scopes are insufficient.
+func (r *Root) verifySecurity(c echo.Context, v SecurityVerifiers, scy map[string][]string) (ok, authenticated bool, err error) {
+	insufficient := false
+	for name, scopes := range scy {
+		d := r.spec.SecurityDefinitions[name]
+		if d == nil {
+			return false, false, nil
+		}
+		valid := false
+		switch SecurityType(d.Type) {
+		case SecurityBasic:
+			username, password, has := c.Request().BasicAuth()
+			if has && v.Basic != nil {
+				valid, err = v.Basic(c, name, username, password)
+			}
+		case "":
+			// mutual TLS, which has no type in Swagger 2.0
+			tls := c.Request().TLS
+			if d.isMutualTLS() && tls != nil && len(tls.PeerCertificates) > 0 && v.MutualTLS != nil {
+				valid, err = v.MutualTLS(c, name, tls.PeerCertificates)
+			}
+		case SecurityAPIKey:
+			if d.isBearer() {
+				token := bearerToken(c)
+				if token != "" && v.Bearer != nil {
+					valid, err = v.Bearer(c, name, token)
+				}
+				break
+			}
+			var key string
+			if d.In == string(SecurityInQuery) {
+				key = c.QueryParam(d.Name)
+			} else {
+				key = c.Request().Header.Get(d.Name)
+			}
+			if key != "" && v.APIKey != nil {
+				valid, err = v.APIKey(c, name, key)
+			}
+		case SecurityOAuth2:
+			token := bearerToken(c)
+			if token != "" && v.OAuth2 != nil {
+				var granted []string
+				valid, granted, err = v.OAuth2(c, name, token)
+				for _, s := range scopes {
+					if !contains(granted, s) {
+						insufficient = true
+					}
+				}
+			}
+		}
+		if err != nil || !valid {
+			return false, false, err
+		}
+	}
+	return !insufficient, insufficient, nil
+}
+
+// bearerToken returns the bearer token in Authorization header
+func bearerToken(c echo.Context) string {
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return auth[7:]
+	}
+	return ""
+}
+
+// checkStrictSecurity returns an error if any scope is not declared by its
+// SecurityDefinition, or any SecurityDefinition is not used by the global
+// security or operations. It is only called with ApiRoot.SetStrictSecurity.
+func (r *Root) checkStrictSecurity() error {
+	used := make(map[string]bool)
+	check := func(security []map[string][]string) error {
+		for _, scy := range security {
+			for k, scopes := range scy {
+				used[k] = true
+				d := r.spec.SecurityDefinitions[k]
+				for _, scope := range scopes {
+					if _, ok := d.Scopes[scope]; !ok {
+						return errors.New("echoswagger: not found scope " + scope + " in SecurityDefinition: " + k)
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := check(r.spec.Security); err != nil {
+		return err
+	}
+	for _, a := range r.transferred {
+		if err := check(a.operation.Security); err != nil {
+			return err
+		}
+	}
+	var unused []string
+	for k := range r.spec.SecurityDefinitions {
+		if !used[k] {
+			unused = append(unused, k)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return errors.New("echoswagger: unused SecurityDefinition: " + strings.Join(unused, ", "))
+}
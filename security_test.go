@@ -1,6 +1,10 @@
 package echoswagger
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -244,3 +248,322 @@ func TestSecurityRepeat(t *testing.T) {
 		assert.Len(t, se, 6)
 	})
 }
+
+func TestEnforceSecurity(t *testing.T) {
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	r.AddSecurityBasic("Basic", "Basic Auth")
+	r.AddSecurityAPIKey("X-Key", "Key", SecurityInHeader)
+	r.AddSecurityOAuth2("OAuth2", "OAuth2 Auth", OAuth2FlowImplicit, "http://petstore.swagger.io/oauth/dialog", "", map[string]string{
+		"read:pets":  "read pets",
+		"write:pets": "modify pets",
+	})
+	e.Use(r.EnforceSecurity(SecurityVerifiers{
+		Basic: func(c echo.Context, name, username, password string) (bool, error) {
+			return username == "admin" && password == "secret", nil
+		},
+		APIKey: func(c echo.Context, name, key string) (bool, error) {
+			if key == "broken" {
+				return false, echo.NewHTTPError(http.StatusServiceUnavailable)
+			}
+			return key == "key", nil
+		},
+		OAuth2: func(c echo.Context, name, token string) (bool, []string, error) {
+			if token == "reader" {
+				return true, []string{"read:pets"}, nil
+			}
+			return token == "writer", []string{"read:pets", "write:pets"}, nil
+		},
+	}))
+
+	h := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	r.GET("/public", h)
+	// Basic AND X-Key, OR OAuth2 with write:pets
+	r.POST("/pets", h).
+		SetSecurity("Basic", "X-Key").
+		SetSecurityWithScope(map[string][]string{"OAuth2": {"write:pets"}})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header map[string]string
+		code   int
+	}{
+		{"Public", echo.GET, "/public", nil, http.StatusOK},
+		{"NoCredentials", echo.POST, "/pets", nil, http.StatusUnauthorized},
+		{"BasicOnly", echo.POST, "/pets", map[string]string{
+			"Authorization": "Basic YWRtaW46c2VjcmV0",
+		}, http.StatusUnauthorized},
+		{"BasicAndKey", echo.POST, "/pets", map[string]string{
+			"Authorization": "Basic YWRtaW46c2VjcmV0",
+			"X-Key":         "key",
+		}, http.StatusOK},
+		{"InvalidKey", echo.POST, "/pets", map[string]string{
+			"Authorization": "Basic YWRtaW46c2VjcmV0",
+			"X-Key":         "wrong",
+		}, http.StatusUnauthorized},
+		{"VerifierError", echo.POST, "/pets", map[string]string{
+			"Authorization": "Basic YWRtaW46c2VjcmV0",
+			"X-Key":         "broken",
+		}, http.StatusServiceUnavailable},
+		{"OAuth2", echo.POST, "/pets", map[string]string{
+			"Authorization": "Bearer writer",
+		}, http.StatusOK},
+		{"InsufficientScope", echo.POST, "/pets", map[string]string{
+			"Authorization": "Bearer reader",
+		}, http.StatusForbidden},
+		{"InvalidToken", echo.POST, "/pets", map[string]string{
+			"Authorization": "Bearer nobody",
+		}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code)
+			if tt.code == http.StatusUnauthorized {
+				assert.Equal(t, "Basic realm=Restricted", rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestEnforceSecurityInvalidSpec(t *testing.T) {
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	r.AddSecurityBasic("Basic", "Basic Auth")
+	e.Use(r.EnforceSecurity(SecurityVerifiers{
+		Basic: func(c echo.Context, name, username, password string) (bool, error) {
+			return false, nil
+		},
+	}))
+
+	h := func(c echo.Context) error {
+		return c.String(http.StatusOK, "secret")
+	}
+	r.GET("/secret", h).SetSecurity("Basic").SetOperationId("duplicate")
+	r.GET("/other", h).SetOperationId("duplicate")
+	e.GET("/undocumented", h)
+
+	req := httptest.NewRequest(echo.GET, "/secret", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+
+	req = httptest.NewRequest(echo.GET, "/undocumented", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestGlobalSecurity(t *testing.T) {
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	r.AddSecurityAPIKey("JWT", "JWT Token", SecurityInHeader)
+	r.AddSecurityBasic("Basic", "Basic Auth")
+	r.SetSecurity("JWT")
+	e.Use(r.EnforceSecurity(SecurityVerifiers{
+		APIKey: func(c echo.Context, name, key string) (bool, error) {
+			return key == "token", nil
+		},
+	}))
+
+	h := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	r.GET("/pets", h)
+	r.GET("/health", h).SetSecurity("Basic").SetNoSecurity()
+	r.Group("Admin", "/admin").SetSecurity("Basic").GET("/users", h)
+	e.GET("/raw", h)
+
+	t.Run("Spec", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/swagger.json", nil))
+		s := rec.Body.String()
+		assert.Contains(t, s, `"security":[{"JWT":[]}]`)
+		assert.Contains(t, s, `"/health":{"get":{"responses":{"default":{"description":"successful operation"}},"security":[]}}`)
+		assert.Contains(t, s, `"/pets":{"get":{"responses":{"default":{"description":"successful operation"}}}}`)
+		assert.Contains(t, s, `"security":[{"Basic":[]}]`)
+	})
+
+	t.Run("Enforce", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/pets", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		req := httptest.NewRequest(echo.GET, "/pets", nil)
+		req.Header.Set("JWT", "token")
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/health", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Undocumented", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/raw", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		req := httptest.NewRequest(echo.GET, "/raw", nil)
+		req.Header.Set("JWT", "token")
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		for _, target := range []string{"/doc/", "/doc/swagger.json", "/doc/oauth2-redirect.html"} {
+			rec = httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, target, nil))
+			assert.Equal(t, http.StatusOK, rec.Code, target)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		r := New(echo.New(), "doc/", nil)
+		r.SetSecurity("JWT")
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/doc/swagger.json", nil), httptest.NewRecorder())
+		assert.Error(t, r.(*Root).genSpec(c))
+	})
+}
+
+func TestSecurityCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r ApiRoot)
+		err   string
+	}{
+		{
+			name: "UnknownScope",
+			setup: func(r ApiRoot) {
+				r.GET("/", nil).SetSecurityWithScope(map[string][]string{"OAuth2": {"read:user"}})
+			},
+			err: "echoswagger: not found scope read:user in SecurityDefinition: OAuth2",
+		},
+		{
+			name: "ScopeOnAPIKey",
+			setup: func(r ApiRoot) {
+				r.Group("G", "/g").SetSecurityWithScope(map[string][]string{"JWT": {"read:users"}}).GET("/", nil)
+			},
+			err: "echoswagger: scopes are only allowed for oauth2 SecurityDefinition: JWT",
+		},
+		{
+			name: "Unused",
+			setup: func(r ApiRoot) {
+				r.GET("/", nil).SetSecurity("JWT")
+			},
+			err: "echoswagger: unused SecurityDefinition: Basic, OAuth2",
+		},
+		{
+			name: "Valid",
+			setup: func(r ApiRoot) {
+				r.SetSecurity("Basic")
+				r.GET("/", nil).SetSecurity("JWT").
+					SetSecurityWithScope(map[string][]string{"OAuth2": {"read:users"}})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(echo.New(), "doc/", nil)
+			r.AddSecurityOAuth2("OAuth2", "OAuth2 Auth", OAuth2FlowAccessCode, "http://petstore.swagger.io/oauth/dialog", "", map[string]string{
+				"read:users": "read users",
+			})
+			r.AddSecurityAPIKey("JWT", "JWT Token", SecurityInHeader)
+			r.AddSecurityBasic("Basic", "Basic Auth")
+			r.SetStrictSecurity(true)
+			tt.setup(r)
+
+			c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/doc/swagger.json", nil), httptest.NewRecorder())
+			err := r.(*Root).genSpec(c)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSecuritySchemes(t *testing.T) {
+	e := echo.New()
+	r := New(e, "doc/", nil)
+	r.AddSecurityBearer("JWT", "JWT Token", "JWT")
+	r.AddSecurityOpenIDConnect("OIDC", "https://example.com/.well-known/openid-configuration")
+	r.AddSecurityMutualTLS("mTLS", "Client Certificate")
+	r.AddSecurityOAuth2Flows("OAuth2", "OAuth2 Auth",
+		OAuth2Flow{
+			Flow:             OAuth2FlowAccessCode,
+			AuthorizationURL: "https://example.com/oauth/authorize",
+			TokenURL:         "https://example.com/oauth/token",
+			Scopes:           map[string]string{"read:pets": "read pets"},
+		},
+		OAuth2Flow{
+			Flow:     OAuth2FlowApplication,
+			TokenURL: "https://example.com/oauth/token",
+			Scopes:   map[string]string{"admin": "administrate"},
+		},
+	)
+	e.Use(r.EnforceSecurity(SecurityVerifiers{
+		Bearer: func(c echo.Context, name, token string) (bool, error) {
+			return name == "OIDC" && token == "id-token", nil
+		},
+		MutualTLS: func(c echo.Context, name string, certs []*x509.Certificate) (bool, error) {
+			return certs[0].Subject.CommonName == "client", nil
+		},
+	}))
+	h := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	r.GET("/pets", h).SetSecurity("JWT").SetSecurity("OIDC").
+		SetSecurityWithScope(map[string][]string{"OAuth2": {"admin"}})
+	r.GET("/certs", h).SetSecurity("mTLS")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/swagger.json", nil))
+	s := rec.Body.String()
+	assert.Contains(t, s, `"JWT":{"description":"JWT Token","in":"header","name":"Authorization","type":"apiKey","x-bearerFormat":"JWT","x-scheme":"bearer"}`)
+	assert.Contains(t, s, `"mTLS":{"description":"Client Certificate","x-scheme":"mutualTLS"}`)
+	assert.Contains(t, s, `"OIDC":{"in":"header","name":"Authorization","type":"apiKey","x-openIdConnectUrl":"https://example.com/.well-known/openid-configuration"}`)
+	assert.Contains(t, s, `"OAuth2":{"authorizationUrl":"https://example.com/oauth/authorize","description":"OAuth2 Auth","flow":"accessCode",`+
+		`"scopes":{"admin":"administrate","read:pets":"read pets"},"tokenUrl":"https://example.com/oauth/token","type":"oauth2",`+
+		`"x-flows":{"accessCode":{"authorizationUrl":"https://example.com/oauth/authorize","tokenUrl":"https://example.com/oauth/token","scopes":{"read:pets":"read pets"}},`+
+		`"application":{"tokenUrl":"https://example.com/oauth/token","scopes":{"admin":"administrate"}}}}`)
+
+	req := httptest.NewRequest(echo.GET, "/pets", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer id-token")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(echo.GET, "/pets", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer other")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	for _, cn := range []string{"client", "other", ""} {
+		req = httptest.NewRequest(echo.GET, "/certs", nil)
+		if cn != "" {
+			req.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}},
+			}
+		}
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if cn == "client" {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		} else {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	}
+}
@@ -1,16 +1,22 @@
 package echoswagger
 
 import (
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/labstack/echo"
 )
 
 const (
 	DefPrefix      = "#/definitions/"
+	ParamPrefix    = "#/parameters/"
+	RespPrefix     = "#/responses/"
 	SwaggerVersion = "2.0"
 	SpecName       = "swagger.json"
 )
@@ -21,54 +27,105 @@ func (r *Root) specHandler(docPath string) echo.HandlerFunc {
 		if err != nil {
 			return c.String(http.StatusInternalServerError, err.Error())
 		}
-		var basePath string
-		if uri, err := url.ParseRequestURI(c.Request().Referer()); err == nil {
-			basePath = trimSuffixSlash(uri.Path, docPath)
-			spec.Host = uri.Host
-		} else {
-			basePath = trimSuffixSlash(c.Request().URL.Path, connectPath(docPath, SpecName))
-			spec.Host = c.Request().Host
-		}
-		spec.BasePath = basePath
+		r.resolveLocation(c, &spec, docPath)
 		return c.JSON(http.StatusOK, spec)
 	}
 }
 
+// resolveLocation sets host, basePath and schemes of spec for the request
+func (r *Root) resolveLocation(c echo.Context, spec *Swagger, docPath string) {
+	req := c.Request()
+	var basePath string
+	if r.forwarded && isTrustedProxy(req, r.trustedProxies) {
+		f := parseForwarded(req)
+		basePath = f.prefix + trimSuffixSlash(req.URL.Path, connectPath(docPath, SpecName))
+		spec.Host = req.Host
+		if f.host != "" {
+			spec.Host = f.host
+		}
+		if f.proto != "" && len(spec.Schemes) == 0 {
+			spec.Schemes = []string{f.proto}
+		}
+	} else if uri, err := url.ParseRequestURI(req.Referer()); err == nil {
+		basePath = trimSuffixSlash(uri.Path, docPath)
+		spec.Host = uri.Host
+	} else {
+		basePath = trimSuffixSlash(req.URL.Path, connectPath(docPath, SpecName))
+		spec.Host = req.Host
+	}
+	spec.BasePath = basePath
+	if r.host != "" {
+		spec.Host = r.host
+	}
+	if r.basePath != "" {
+		spec.BasePath = r.basePath
+	}
+}
+
 // Generate swagger spec data, without host & basePath info
 func (r *Root) GetSpec(c echo.Context, docPath string) (Swagger, error) {
+	if err := r.build(c); err != nil {
+		return Swagger{}, err
+	}
+	return r.filterSpec(docPath), nil
+}
+
+// build generates swagger spec data only once
+func (r *Root) build(c echo.Context) error {
 	r.once.Do(func() {
 		r.err = r.genSpec(c)
 		r.cleanUp()
 	})
-	if r.err != nil {
-		return Swagger{}, r.err
-	}
-	return *r.spec, nil
+	return r.err
 }
 
 func (r *Root) genSpec(c echo.Context) error {
 	r.spec.Swagger = SwaggerVersion
 	r.spec.Paths = make(map[string]interface{})
+	r.routes = make(map[string]*echo.Route)
+	r.operationIds = make(map[string]*echo.Route)
+	r.transferred = nil
+	r.operations = make(map[string]*Operation)
 
-	for i := range r.groups {
-		group := &r.groups[i]
-		r.spec.Tags = append(r.spec.Tags, &group.tag)
-		for j := range group.apis {
-			a := &group.apis[j]
-			if err := a.operation.addSecurity(r.spec.SecurityDefinitions, group.security); err != nil {
-				return err
-			}
-			if err := r.transfer(a); err != nil {
-				return err
-			}
+	global := &Operation{}
+	if err := global.addSecurity(r.spec.SecurityDefinitions, r.security); err != nil {
+		return err
+	}
+	r.spec.Security = global.Security
+
+	var nested bool
+	for _, group := range r.groups {
+		if err := r.transferGroup(group); err != nil {
+			return err
+		}
+		if len(group.tagNames()) > 1 {
+			nested = true
 		}
 	}
+	if nested {
+		for _, group := range r.groups {
+			r.spec.TagGroups = append(r.spec.TagGroups, &TagGroup{
+				Name: group.tag.Name,
+				Tags: group.tagNames(),
+			})
+		}
+	}
+	r.addTags()
 
-	for i := range r.apis {
-		if err := r.transfer(&r.apis[i]); err != nil {
+	for _, a := range r.apis {
+		if err := r.transfer(a); err != nil {
+			return err
+		}
+	}
+	if err := r.handleUndocumented(); err != nil {
+		return err
+	}
+	if r.strictSecurity {
+		if err := r.checkStrictSecurity(); err != nil {
 			return err
 		}
 	}
+	r.genOperationIds()
 
 	for k, v := range *r.defs {
 		r.spec.Definitions[k] = v.Schema
@@ -76,29 +133,269 @@ func (r *Root) genSpec(c echo.Context) error {
 	return nil
 }
 
+// addTags adds tags of ApiRoot to the spec and sorts all tags
+func (r *Root) addTags() {
+LoopTags:
+	for _, t := range r.tags {
+		for _, st := range r.spec.Tags {
+			if st.Name != t.Name {
+				continue
+			}
+			if st.Description == "" {
+				st.Description = t.Description
+			}
+			if st.ExternalDocs == nil {
+				st.ExternalDocs = t.ExternalDocs
+			}
+			continue LoopTags
+		}
+		r.spec.Tags = append(r.spec.Tags, t)
+		if len(r.spec.TagGroups) > 0 {
+			r.spec.TagGroups = append(r.spec.TagGroups, &TagGroup{
+				Name: t.Name,
+				Tags: []string{t.Name},
+			})
+		}
+	}
+
+	if len(r.tagOrder) == 0 {
+		return
+	}
+	index := func(name string) int {
+		for i, n := range r.tagOrder {
+			if n == name {
+				return i
+			}
+		}
+		return len(r.tagOrder)
+	}
+	sort.SliceStable(r.spec.Tags, func(i, j int) bool {
+		return index(r.spec.Tags[i].Name) < index(r.spec.Tags[j].Name)
+	})
+	sort.SliceStable(r.spec.TagGroups, func(i, j int) bool {
+		return index(r.spec.TagGroups[i].Name) < index(r.spec.TagGroups[j].Name)
+	})
+}
+
+func (r *Root) transferGroup(g *group) error {
+	if !g.flatten {
+		r.spec.Tags = append(r.spec.Tags, &g.tag)
+	}
+	for _, a := range g.apis {
+		// before inheriting, so responses of groups don't replace the default
+		a.operation.addDefaultResponse()
+		if !contains(a.operation.Tags, g.tagName()) {
+			a.operation.Tags = append([]string{g.tagName()}, a.operation.Tags...)
+		}
+		for p := g; p != nil; p = p.parent {
+			a.hidden = a.hidden || p.hidden
+			if len(a.audiences) == 0 {
+				a.audiences = p.audiences
+			}
+			a.operation.inherit(&p.operation)
+			if a.noSecurity {
+				continue
+			}
+			if err := a.operation.addSecurity(r.spec.SecurityDefinitions, p.security); err != nil {
+				return err
+			}
+		}
+		if err := r.transfer(a); err != nil {
+			return err
+		}
+	}
+	for _, child := range g.groups {
+		if err := r.transferGroup(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *Root) transfer(a *api) error {
-	if err := a.operation.addSecurity(r.spec.SecurityDefinitions, a.security); err != nil {
+	if a.noSecurity {
+		a.operation.Security = make([]map[string][]string, 0)
+	} else if err := a.operation.addSecurity(r.spec.SecurityDefinitions, a.security); err != nil {
+		return err
+	}
+	if err := r.checkRefs(&a.operation); err != nil {
 		return err
 	}
 
 	path := toSwaggerPath(a.route.Path)
-	if len(a.operation.Responses) == 0 {
-		a.operation.Responses["default"] = &Response{
+	if err := r.checkDuplicate(a, path); err != nil {
+		return err
+	}
+	a.operation.addDefaultResponse()
+
+	r.transferred = append(r.transferred, a)
+	r.operations[operationKey(a.route.Method, a.route.Path)] = &a.operation
+	if a.hidden {
+		r.hidden[&a.operation] = true
+	}
+	if len(a.audiences) > 0 {
+		r.audiences[&a.operation] = a.audiences
+	}
+	p, ok := r.spec.Paths[path]
+	if !ok {
+		p = &Path{}
+		r.spec.Paths[path] = p
+	}
+	return p.(*Path).oprationAssign(a.route.Method, &a.operation)
+}
+
+// addDefaultResponse adds the default response if o has no responses
+func (o *Operation) addDefaultResponse() {
+	if len(o.Responses) == 0 {
+		o.Responses["default"] = &Response{
 			Description: "successful operation",
 		}
 	}
+}
 
-	if p, ok := r.spec.Paths[path]; ok {
-		p.(*Path).oprationAssign(a.route.Method, &a.operation)
-	} else {
-		p := &Path{}
-		p.oprationAssign(a.route.Method, &a.operation)
-		r.spec.Paths[path] = p
+// checkDuplicate reports whether the method and path or the operationId
+// of api are used by another transferred route
+func (r *Root) checkDuplicate(a *api, path string) error {
+	// paths which differ only in names of parameters are equivalent
+	key := a.route.Method + " " + pathTemplate(path)
+	if rt, ok := r.routes[key]; ok {
+		return errors.New("echoswagger: duplicate route " + a.route.Method + " " + path + ": " +
+			routeString(rt) + " and " + routeString(a.route))
+	}
+	r.routes[key] = a.route
+
+	id := a.operation.OperationID
+	if id == "" {
+		return nil
+	}
+	if rt, ok := r.operationIds[id]; ok {
+		return errors.New("echoswagger: duplicate operationId " + id + ": " +
+			routeString(rt) + " and " + routeString(a.route))
+	}
+	r.operationIds[id] = a.route
+	return nil
+}
+
+// handleUndocumented finds routes of Echo which are not transferred
+// and handles them according to the UndocumentedMode
+func (r *Root) handleUndocumented() error {
+	if r.undocumented != UndocumentedWarn && r.undocumented != UndocumentedFail &&
+		r.undocumented != UndocumentedInclude {
+		return nil
+	}
+
+	var routes []*echo.Route
+	for _, rt := range r.echo.Routes() {
+		// Routes registered by `Group#Use()` to make group middlewares work
+		if strings.HasPrefix(rt.Name, "github.com/labstack/echo.(*Group).Use.") {
+			continue
+		}
+		// Routes registered by this or other ApiRoots on the same Echo
+		if !isOwnedRoute(r.echo, rt) {
+			routes = append(routes, rt)
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	switch r.undocumented {
+	case UndocumentedWarn:
+		for _, rt := range routes {
+			r.echo.Logger.Warn("echoswagger: undocumented route " + routeString(rt))
+		}
+	case UndocumentedFail:
+		s := make([]string, len(routes))
+		for i, rt := range routes {
+			s[i] = routeString(rt)
+		}
+		return errors.New("echoswagger: undocumented routes: " + strings.Join(s, ", "))
+	case UndocumentedInclude:
+		for _, rt := range routes {
+			a := &api{
+				route: rt,
+				operation: Operation{
+					Tags:      []string{UndocumentedTag},
+					Responses: make(map[string]*Response),
+				},
+			}
+			if err := r.transfer(a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// genOperationIds generates operationIds by operationIDFunc for
+// transferred operations which have no operationId. Operations with
+// colliding operationIds fall back to OperationIDFromPath.
+func (r *Root) genOperationIds() {
+	if r.operationIDFunc == nil {
+		return
+	}
+	ids := make(map[*api]string)
+	count := make(map[string]int)
+	for _, a := range r.transferred {
+		if a.operation.OperationID != "" {
+			continue
+		}
+		if id := r.operationIDFunc(a.route, &a.operation); id != "" {
+			ids[a] = id
+			count[id]++
+		}
+	}
+	for _, a := range r.transferred {
+		id, ok := ids[a]
+		if !ok {
+			continue
+		}
+		if _, used := r.operationIds[id]; used || count[id] > 1 {
+			id = OperationIDFromPath(a.route, &a.operation)
+		}
+		for {
+			if _, ok := r.operationIds[id]; !ok {
+				break
+			}
+			id += "_"
+		}
+		a.operation.OperationID = id
+		r.operationIds[id] = a.route
+	}
+}
+
+// checkRefs reports whether referenced root-level parameters and
+// responses of operation are defined
+func (r *Root) checkRefs(operation *Operation) error {
+	for _, p := range operation.Parameters {
+		if p.Ref == "" {
+			continue
+		}
+		if _, ok := r.spec.Parameters[p.Ref[len(ParamPrefix):]]; !ok {
+			return errors.New("echoswagger: not found Parameter with name: " + p.Ref[len(ParamPrefix):])
+		}
+	}
+	for _, resp := range operation.Responses {
+		if resp.Ref == "" {
+			continue
+		}
+		if _, ok := r.spec.Responses[resp.Ref[len(RespPrefix):]]; !ok {
+			return errors.New("echoswagger: not found Response with name: " + resp.Ref[len(RespPrefix):])
+		}
 	}
 	return nil
 }
 
-func (p *Path) oprationAssign(method string, operation *Operation) {
+// oprationAssign assigns operation to the path. Methods which are not
+// supported by the spec, such as TRACE, CONNECT or custom methods,
+// are assigned with the extension "x-<method>".
+func (p *Path) oprationAssign(method string, operation *Operation) error {
 	switch method {
 	case echo.GET:
 		p.Get = operation
@@ -114,10 +411,23 @@ func (p *Path) oprationAssign(method string, operation *Operation) {
 		p.Head = operation
 	case echo.PATCH:
 		p.Patch = operation
+	default:
+		if !isValidMethod(method) {
+			return errors.New("echoswagger: unsupported method: " + method)
+		}
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions["x-"+strings.ToLower(method)] = operation
 	}
+	return nil
 }
 
 func (r *Root) cleanUp() {
+	r.routes = nil
+	r.operationIds = nil
+	r.transferred = nil
+	releaseRoutes(r.echo)
 	r.echo = nil
 	r.groups = nil
 	r.apis = nil
@@ -127,7 +437,13 @@ func (r *Root) cleanUp() {
 // addDefinition adds definition specification and returns
 // key of RawDefineDic
 func (r *RawDefineDic) addDefinition(v reflect.Value) string {
-	exist, key := r.getKey(v)
+	return r.addNamedDefinition(v, v.Type().Name())
+}
+
+// addNamedDefinition adds definition specification with name, it is
+// used for types without names such as those built by reflect.StructOf.
+func (r *RawDefineDic) addNamedDefinition(v reflect.Value, name string) string {
+	exist, key := r.getKey(v, name)
 	if exist {
 		return key
 	}
@@ -148,7 +464,7 @@ func (r *RawDefineDic) addDefinition(v reflect.Value) string {
 		schema.XML = &XMLSchema{}
 	}
 	if schema.XML.Name == "" {
-		schema.XML.Name = v.Type().Name()
+		schema.XML.Name = name
 	}
 	return key
 }
@@ -179,3 +495,65 @@ func (r *RawDefineDic) handleStruct(v reflect.Value, schema *JSONSchema) {
 		schema.handleSwaggerTags(f, name)
 	}
 }
+
+// MarshalJSON omits the other fields of a Parameter which references
+// a global API parameter.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	if p.Ref != "" {
+		return json.Marshal(map[string]string{"$ref": p.Ref})
+	}
+	type parameter Parameter
+	return json.Marshal(parameter(p))
+}
+
+// MarshalJSON emits an empty security of public operation,
+// which overrides the global security.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	v := struct {
+		operation
+		Security *[]map[string][]string `json:"security,omitempty"`
+	}{operation: operation(o)}
+	if o.Security != nil {
+		v.Security = &o.Security
+	}
+	return marshalWithExtensions(v, o.Extensions)
+}
+
+// MarshalJSON adds the extensions to a SecurityDefinition,
+// mutual TLS is emitted with only description and extensions.
+func (s SecurityDefinition) MarshalJSON() ([]byte, error) {
+	if s.isMutualTLS() {
+		v := struct {
+			Description string `json:"description,omitempty"`
+		}{s.Description}
+		return marshalWithExtensions(v, s.Extensions)
+	}
+	type securityDefinition SecurityDefinition
+	return marshalWithExtensions(securityDefinition(s), s.Extensions)
+}
+
+// MarshalJSON adds the extensions to a Path.
+func (p Path) MarshalJSON() ([]byte, error) {
+	type path Path
+	return marshalWithExtensions(path(p), p.Extensions)
+}
+
+// marshalWithExtensions marshals v which must be an object and
+// merges extensions into it.
+func marshalWithExtensions(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return b, err
+	}
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, e := range extensions {
+		if m[k], err = json.Marshal(e); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(m)
+}
@@ -1,6 +1,7 @@
 package echoswagger
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -84,6 +86,32 @@ func TestSpec(t *testing.T) {
 		}
 	})
 
+	t.Run("ExtensionMethods", func(t *testing.T) {
+		r := prepareApiRoot()
+		var h echo.HandlerFunc
+		r.GET("/", h)
+		r.TRACE("/", h)
+		r.CONNECT("/", h)
+		r.Add(echo.PROPFIND, "/", h)
+		e := r.(*Root).echo
+		req := httptest.NewRequest(echo.GET, "/doc/swagger.json", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		j := `{"swagger":"2.0","info":{"title":"Project APIs","version":""},"host":"example.com","paths":{"/":{"get":{"responses":{"default":{"description":"successful operation"}}},"x-trace":{"responses":{"default":{"description":"successful operation"}}},"x-connect":{"responses":{"default":{"description":"successful operation"}}},"x-propfind":{"responses":{"default":{"description":"successful operation"}}}}}}`
+		if assert.NoError(t, r.(*Root).specHandler("/doc")(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, j, rec.Body.String())
+		}
+	})
+
+	t.Run("InvalidMethod", func(t *testing.T) {
+		r := prepareApiRoot()
+		var h echo.HandlerFunc
+		r.Add("BAD METHOD", "/", h)
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		assert.EqualError(t, r.(*Root).genSpec(c), "echoswagger: unsupported method: BAD METHOD")
+	})
+
 	t.Run("CleanUp", func(t *testing.T) {
 		r := prepareApiRoot()
 		e := r.(*Root).echo
@@ -111,6 +139,213 @@ func TestSpec(t *testing.T) {
 	})
 }
 
+func TestSpecRefs(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		r := prepareApiRoot()
+		r.DefineParameter("X-Request-ID", ParamInHeader, "", "", true).
+			DefineResponse("Error", "error", nil, nil)
+		var h echo.HandlerFunc
+		r.GET("/ping", h).
+			UseParameter("X-Request-ID").
+			UseResponse(http.StatusInternalServerError, "Error")
+
+		e := r.(*Root).echo
+		req := httptest.NewRequest(echo.GET, "/doc/swagger.json", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		j := `{"swagger":"2.0","info":{"title":"Project APIs","version":""},"host":"example.com","paths":{"/ping":{"get":{"parameters":[{"$ref":"#/parameters/X-Request-ID"}],"responses":{"500":{"$ref":"#/responses/Error"}}}}},"parameters":{"X-Request-ID":{"name":"X-Request-ID","in":"header","required":true,"type":"string","format":"string"}},"responses":{"Error":{"description":"error"}}}`
+		if assert.NoError(t, r.(*Root).specHandler("/doc")(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, j, rec.Body.String())
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		r := prepareApiRoot()
+		var h echo.HandlerFunc
+		r.GET("/param", h).UseParameter("limit")
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		assert.Error(t, r.(*Root).genSpec(c))
+
+		r = prepareApiRoot()
+		r.GET("/resp", h).UseResponse(http.StatusNotFound, "NotFound")
+		c = r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		assert.Error(t, r.(*Root).genSpec(c))
+	})
+}
+
+func TestSpecGroupDefaults(t *testing.T) {
+	type paging struct {
+		Page int `query:"page"`
+		Size int `query:"size"`
+	}
+	r := prepareApiRoot()
+	g := r.Group("Admin", "/admin").
+		AddParamHeader("", "X-Tenant", "Tenant ID", true).
+		AddParamQueryNested(&paging{}).
+		AddResponse(http.StatusUnauthorized, "unauthorized", nil, nil).
+		AddResponse(http.StatusForbidden, "forbidden", nil, nil).
+		SetRequestContentType("application/json").
+		SetResponseContentType("application/json")
+
+	var h echo.HandlerFunc
+	g.GET("/users", h)
+	g.POST("/users", h).
+		AddParamQuery(0, "page", "Page of users", true).
+		AddResponse(http.StatusForbidden, "not an admin", nil, nil).
+		SetRequestContentType("application/xml")
+
+	c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+	if assert.NoError(t, r.(*Root).genSpec(c)) {
+		p := r.(*Root).spec.Paths["/admin/users"].(*Path)
+
+		assert.Len(t, p.Get.Parameters, 3)
+		assert.Len(t, p.Get.Responses, 3)
+		assert.Equal(t, "successful operation", p.Get.Responses["default"].Description)
+		assert.Equal(t, []string{"application/json"}, p.Get.Consumes)
+		assert.Equal(t, []string{"application/json"}, p.Get.Produces)
+
+		assert.Len(t, p.Post.Parameters, 3)
+		assert.Equal(t, "Page of users", p.Post.Parameters[0].Description)
+		assert.Equal(t, "not an admin", p.Post.Responses["403"].Description)
+		assert.Equal(t, "unauthorized", p.Post.Responses["401"].Description)
+		assert.NotContains(t, p.Post.Responses, "default")
+		assert.Equal(t, []string{"application/xml"}, p.Post.Consumes)
+		assert.Equal(t, []string{"application/json"}, p.Post.Produces)
+	}
+}
+
+func TestSpecDuplicate(t *testing.T) {
+	ha := func(c echo.Context) error { return nil }
+	hb := func(c echo.Context) error { return nil }
+
+	t.Run("Route", func(t *testing.T) {
+		r := prepareApiRoot()
+		r.GET("/users/:id", ha)
+		r.Group("Users", "/users").GET("/:id", hb)
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		err := r.(*Root).genSpec(c)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "duplicate route GET /users/{id}")
+			assert.Contains(t, err.Error(), "TestSpecDuplicate.func1")
+			assert.Contains(t, err.Error(), "TestSpecDuplicate.func2")
+		}
+	})
+
+	t.Run("ParamName", func(t *testing.T) {
+		r := prepareApiRoot()
+		r.GET("/users/:id", ha)
+		r.GET("/users/:name", hb)
+		r.GET("/users/:id/pets", hb)
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		err := r.(*Root).genSpec(c)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "duplicate route GET /users/{name}")
+			assert.NotContains(t, err.Error(), "/pets")
+		}
+	})
+
+	t.Run("OperationId", func(t *testing.T) {
+		r := prepareApiRoot()
+		r.GET("/users", ha).SetOperationId("getUsers")
+		r.POST("/users", hb).SetOperationId("getUsers")
+		c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		err := r.(*Root).genSpec(c)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "duplicate operationId getUsers")
+			assert.Contains(t, err.Error(), "GET /users")
+			assert.Contains(t, err.Error(), "POST /users")
+		}
+	})
+}
+
+func TestSpecUndocumented(t *testing.T) {
+	var h echo.HandlerFunc
+	prepare := func(mode UndocumentedMode) ApiRoot {
+		r := prepareApiRoot()
+		r.SetUndocumentedRoutes(mode)
+		r.GET("/documented", h)
+		g := r.Group("G", "/g", func(next echo.HandlerFunc) echo.HandlerFunc { return next })
+		g.GET("/documented", h)
+		e := r.Echo()
+		e.GET("/undocumented", h)
+		g.EchoGroup().POST("/undocumented", h)
+		return r
+	}
+
+	t.Run("Ignore", func(t *testing.T) {
+		r := prepare(UndocumentedIgnore)
+		c := r.Echo().NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		if assert.NoError(t, r.(*Root).genSpec(c)) {
+			assert.Len(t, r.(*Root).spec.Paths, 2)
+		}
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		r := prepare(UndocumentedWarn)
+		buf := new(bytes.Buffer)
+		r.Echo().Logger.SetOutput(buf)
+		r.Echo().Logger.SetLevel(log.WARN)
+		c := r.Echo().NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		if assert.NoError(t, r.(*Root).genSpec(c)) {
+			assert.Len(t, r.(*Root).spec.Paths, 2)
+			assert.Contains(t, buf.String(), "undocumented route GET /undocumented")
+			assert.Contains(t, buf.String(), "undocumented route POST /g/undocumented")
+		}
+	})
+
+	t.Run("Fail", func(t *testing.T) {
+		r := prepare(UndocumentedFail)
+		c := r.Echo().NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		err := r.(*Root).genSpec(c)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "GET /undocumented")
+			assert.Contains(t, err.Error(), "POST /g/undocumented")
+			assert.NotContains(t, err.Error(), "/doc")
+		}
+	})
+
+	t.Run("Include", func(t *testing.T) {
+		r := prepare(UndocumentedInclude)
+		c := r.Echo().NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		if assert.NoError(t, r.(*Root).genSpec(c)) {
+			s := r.(*Root).spec
+			assert.Len(t, s.Paths, 4)
+			assert.Equal(t, []string{UndocumentedTag}, s.Paths["/undocumented"].(*Path).Get.Tags)
+			assert.NotNil(t, s.Paths["/g/undocumented"].(*Path).Post)
+		}
+	})
+
+	t.Run("MultipleRoots", func(t *testing.T) {
+		r := prepare(UndocumentedFail)
+		other := New(r.Echo(), "doc2", nil)
+		other.GET("/b", h)
+		other.Group("B", "/gb").GET("/documented", h)
+		c := r.Echo().NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+		err := r.(*Root).genSpec(c)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "GET /undocumented")
+			assert.NotContains(t, err.Error(), "/b")
+			assert.NotContains(t, err.Error(), "/doc2")
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		e := echo.New()
+		New(e, "doc", nil).SetUndocumentedRoutes(UndocumentedFail).GET("/a", h)
+		New(e, "doc2", nil).SetUndocumentedRoutes(UndocumentedFail).GET("/b", h)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/swagger.json", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, ownedRoutes.m, e)
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc2/swagger.json", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, ownedRoutes.m, e)
+	})
+}
+
 func TestReferer(t *testing.T) {
 	tests := []struct {
 		name, referer, host, docPath, basePath string
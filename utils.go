@@ -3,6 +3,8 @@ package echoswagger
 import (
 	"reflect"
 	"strings"
+
+	"github.com/labstack/echo"
 )
 
 func contains(list []string, s string) bool {
@@ -104,3 +106,8 @@ func trimSuffixSlash(s, suffix string) string {
 	suffix = removeTrailingSlash(suffix)
 	return strings.TrimSuffix(s, suffix)
 }
+
+// routeString describes route with its method, path and handler name
+func routeString(route *echo.Route) string {
+	return route.Method + " " + route.Path + " (" + route.Name + ")"
+}
@@ -0,0 +1,350 @@
+package echoswagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes a value which violates the spec.
+type ValidationError struct {
+	// In is the location of the value, e.g. "query", "body" or "response".
+	In string `json:"in"`
+	// Name is the name of parameter or the path of property, e.g. "body.tags[0].name".
+	Name string `json:"name"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.In + " " + e.Name + ": " + e.Message
+}
+
+// ValidationErrors is the body of response for requests which fail the validation.
+type ValidationErrors struct {
+	Message string             `json:"message"`
+	Errors  []*ValidationError `json:"errors"`
+}
+
+func (e *ValidationErrors) Error() string {
+	s := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		s[i] = err.Error()
+	}
+	return e.Message + ": " + strings.Join(s, "; ")
+}
+
+// constraints are validation properties shared by Parameter, Header and Items
+type constraints struct {
+	Type             string
+	Format           string
+	Items            *Items
+	CollectionFormat string
+	Maximum          *float64
+	ExclusiveMaximum bool
+	Minimum          *float64
+	ExclusiveMinimum bool
+	MaxLength        *int
+	MinLength        *int
+	Pattern          string
+	MaxItems         *int
+	MinItems         *int
+	UniqueItems      bool
+	Enum             []interface{}
+	MultipleOf       float64
+}
+
+func (p *Parameter) constraints() constraints {
+	return constraints{
+		Type: p.Type, Format: p.Format, Items: p.Items, CollectionFormat: p.CollectionFormat,
+		Maximum: p.Maximum, ExclusiveMaximum: p.ExclusiveMaximum,
+		Minimum: p.Minimum, ExclusiveMinimum: p.ExclusiveMinimum,
+		MaxLength: p.MaxLength, MinLength: p.MinLength, Pattern: p.Pattern,
+		MaxItems: p.MaxItems, MinItems: p.MinItems, UniqueItems: p.UniqueItems,
+		Enum: p.Enum, MultipleOf: p.MultipleOf,
+	}
+}
+
+func (h *Header) constraints() constraints {
+	return constraints{
+		Type: h.Type, Format: h.Format, Items: h.Items, CollectionFormat: h.CollectionFormat,
+		Maximum: h.Maximum, ExclusiveMaximum: h.ExclusiveMaximum,
+		Minimum: h.Minimum, ExclusiveMinimum: h.ExclusiveMinimum,
+		MaxLength: h.MaxLength, MinLength: h.MinLength, Pattern: h.Pattern,
+		MaxItems: h.MaxItems, MinItems: h.MinItems, UniqueItems: h.UniqueItems,
+		Enum: h.Enum, MultipleOf: h.MultipleOf,
+	}
+}
+
+func (t *Items) constraints() constraints {
+	return constraints{
+		Type: t.Type, Format: t.Format, Items: t.Items, CollectionFormat: t.CollectionFormat,
+		Maximum: t.Maximum, ExclusiveMaximum: t.ExclusiveMaximum,
+		Minimum: t.Minimum, ExclusiveMinimum: t.ExclusiveMinimum,
+		MaxLength: t.MaxLength, MinLength: t.MinLength, Pattern: t.Pattern,
+		MaxItems: t.MaxItems, MinItems: t.MinItems, UniqueItems: t.UniqueItems,
+		Enum: t.Enum, MultipleOf: t.MultipleOf,
+	}
+}
+
+// validateValues validates values of a non-body parameter or header,
+// values of "multi" collection format are passed separately
+func (c constraints) validateValues(values []string) []string {
+	if c.Type != "array" {
+		if len(values) == 0 {
+			return nil
+		}
+		return c.validateString(values[0])
+	}
+
+	var items []string
+	switch c.CollectionFormat {
+	case "multi":
+		items = values
+	case "ssv":
+		items = splitValues(values, " ")
+	case "tsv":
+		items = splitValues(values, "\t")
+	case "pipes":
+		items = splitValues(values, "|")
+	default:
+		items = splitValues(values, ",")
+	}
+	var msgs []string
+	if c.MinItems != nil && len(items) < *c.MinItems {
+		msgs = append(msgs, fmt.Sprintf("should have at least %d items", *c.MinItems))
+	}
+	if c.MaxItems != nil && len(items) > *c.MaxItems {
+		msgs = append(msgs, fmt.Sprintf("should have at most %d items", *c.MaxItems))
+	}
+	if c.UniqueItems {
+		seen := make(map[string]bool)
+		for _, v := range items {
+			if seen[v] {
+				msgs = append(msgs, "should have unique items")
+				break
+			}
+			seen[v] = true
+		}
+	}
+	if c.Items != nil {
+		ic := c.Items.constraints()
+		for i, v := range items {
+			for _, m := range ic.validateValues([]string{v}) {
+				msgs = append(msgs, fmt.Sprintf("item %d %s", i, m))
+			}
+		}
+	}
+	return msgs
+}
+
+// validateString validates a single value in string form
+func (c constraints) validateString(s string) []string {
+	switch c.Type {
+	case "integer":
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return []string{"should be an integer"}
+		}
+		return c.validateNumber(float64(v))
+	case "number":
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return []string{"should be a number"}
+		}
+		return c.validateNumber(v)
+	case "boolean":
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return []string{"should be a boolean"}
+		}
+		if len(c.Enum) > 0 && !containsValue(c.Enum, v) {
+			return []string{fmt.Sprintf("should be one of %v", c.Enum)}
+		}
+		return nil
+	default:
+		return c.validateText(s)
+	}
+}
+
+func (c constraints) validateNumber(v float64) []string {
+	var msgs []string
+	if c.Minimum != nil && (v < *c.Minimum || c.ExclusiveMinimum && v == *c.Minimum) {
+		msgs = append(msgs, fmt.Sprintf("should be greater than %v", *c.Minimum))
+	}
+	if c.Maximum != nil && (v > *c.Maximum || c.ExclusiveMaximum && v == *c.Maximum) {
+		msgs = append(msgs, fmt.Sprintf("should be less than %v", *c.Maximum))
+	}
+	if c.MultipleOf > 0 && math.Mod(v, c.MultipleOf) != 0 {
+		msgs = append(msgs, fmt.Sprintf("should be a multiple of %v", c.MultipleOf))
+	}
+	if len(c.Enum) > 0 && !containsValue(c.Enum, v) {
+		msgs = append(msgs, fmt.Sprintf("should be one of %v", c.Enum))
+	}
+	return msgs
+}
+
+func (c constraints) validateText(s string) []string {
+	var msgs []string
+	if c.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			msgs = append(msgs, "should be a date-time")
+		}
+	}
+	l := utf8.RuneCountInString(s)
+	if c.MinLength != nil && l < *c.MinLength {
+		msgs = append(msgs, fmt.Sprintf("should be at least %d characters", *c.MinLength))
+	}
+	if c.MaxLength != nil && l > *c.MaxLength {
+		msgs = append(msgs, fmt.Sprintf("should be at most %d characters", *c.MaxLength))
+	}
+	if c.Pattern != "" {
+		if re, err := regexp.Compile(c.Pattern); err == nil && !re.MatchString(s) {
+			msgs = append(msgs, "should match pattern "+c.Pattern)
+		}
+	}
+	if len(c.Enum) > 0 && !containsValue(c.Enum, s) {
+		msgs = append(msgs, fmt.Sprintf("should be one of %v", c.Enum))
+	}
+	return msgs
+}
+
+// schemaValidator validates decoded JSON values against JSONSchema
+type schemaValidator struct {
+	in   string
+	defs map[string]*JSONSchema
+	// strict reports properties which are not declared by the schema
+	strict bool
+}
+
+// validate validates v against schema, name is the path of v
+func (sv *schemaValidator) validate(v interface{}, schema *JSONSchema, name string) []*ValidationError {
+	if schema == nil || v == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		return sv.validate(v, sv.defs[schema.Ref[len(DefPrefix):]], name)
+	}
+	in := sv.in
+
+	fail := func(msg string) []*ValidationError {
+		return []*ValidationError{{In: in, Name: name, Message: msg}}
+	}
+	c := constraints{
+		Type: string(schema.Type), Format: schema.Format,
+		Maximum: schema.Maximum, Minimum: schema.Minimum,
+		MaxLength: schema.MaxLength, MinLength: schema.MinLength,
+		Pattern: schema.Pattern, Enum: schema.Enum,
+	}
+	var errs []*ValidationError
+	var msgs []string
+	switch schema.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fail("should be an object")
+		}
+		for _, r := range schema.Required {
+			if _, ok := m[r]; !ok {
+				errs = append(errs, &ValidationError{In: in, Name: joinName(name, r), Message: "is required"})
+			}
+		}
+		for k, pv := range m {
+			if ps, ok := schema.Properties[k]; ok {
+				errs = append(errs, sv.validate(pv, ps, joinName(name, k))...)
+			} else if schema.AdditionalProperties != nil {
+				errs = append(errs, sv.validate(pv, schema.AdditionalProperties, joinName(name, k))...)
+			} else if sv.strict {
+				errs = append(errs, &ValidationError{In: in, Name: joinName(name, k), Message: "is not declared"})
+			}
+		}
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return fail("should be an array")
+		}
+		for i, iv := range a {
+			errs = append(errs, sv.validate(iv, schema.Items, fmt.Sprintf("%s[%d]", name, i))...)
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fail("should be a " + string(schema.Type))
+		}
+		msgs = c.validateString(n.String())
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("should be a boolean")
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fail("should be a string")
+		}
+		msgs = c.validateText(s)
+	}
+	for _, m := range msgs {
+		errs = append(errs, &ValidationError{In: in, Name: name, Message: m})
+	}
+	return errs
+}
+
+func joinName(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
+
+func splitValues(values []string, sep string) []string {
+	var items []string
+	for _, v := range values {
+		items = append(items, strings.Split(v, sep)...)
+	}
+	return items
+}
+
+// containsValue reports whether enum contains v, numbers are compared by value,
+// and v is rounded to the precision of float32 enums
+func containsValue(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if fe, ok := e.(float32); ok {
+			if fv, ok := toFloat(v); ok && float32(fv) == fe {
+				return true
+			}
+			continue
+		}
+		if fe, ok := toFloat(e); ok {
+			if fv, ok := toFloat(v); ok && fe == fv {
+				return true
+			}
+			continue
+		}
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -130,3 +131,18 @@ func isValidScheme(s string) bool {
 	}
 	return false
 }
+
+// isValidMethod reports whether s is a valid HTTP method token.
+// See: https://tools.ietf.org/html/rfc7230#section-3.2.6
+func isValidMethod(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return true
+}
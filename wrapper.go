@@ -1,8 +1,9 @@
 package echoswagger
 
 import (
-	"reflect"
+	"net"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/labstack/echo"
@@ -11,7 +12,6 @@ import (
 /*
 TODO:
 1.pattern
-2.opreationId 重复判断
 
 Notice:
 1.不会对Email和URL进行验证，因为不影响页面的正常显示
@@ -44,6 +44,22 @@ type ApiRouter interface {
 
 	// PATCH overrides `Echo#PATCH()` and creates Api.
 	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api
+
+	// CONNECT overrides `Echo#CONNECT()` and creates Api.
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api
+
+	// TRACE overrides `Echo#TRACE()` and creates Api.
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api
+
+	// Any overrides `Echo#Any()` and creates Api for all HTTP methods.
+	// The returned Api applies to all the operations,
+	// and its Route method returns the first route.
+	Any(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api
+
+	// Match overrides `Echo#Match()` and creates Api for given HTTP methods.
+	// The returned Api applies to all the operations,
+	// and its Route method returns the first route.
+	Match(methods []string, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api
 }
 
 type ApiRoot interface {
@@ -76,6 +92,100 @@ type ApiRoot interface {
 	// AddSecurityOAuth2 adds `SecurityDefinition` with type oauth2.
 	AddSecurityOAuth2(name, desc string, flow OAuth2FlowType, authorizationUrl, tokenUrl string, scopes map[string]string) ApiRoot
 
+	// AddSecurityOAuth2Flows adds `SecurityDefinition` with type oauth2 which
+	// supports multiple flows. Swagger 2.0 allows only one flow, so the first
+	// flow is used with scopes of all flows, and all flows are listed in the
+	// extension "x-flows".
+	AddSecurityOAuth2Flows(name, desc string, flows ...OAuth2Flow) ApiRoot
+
+	// AddSecurityBearer adds `SecurityDefinition` of HTTP bearer authentication.
+	// It is emitted as type apikey in the Authorization header, with
+	// extensions "x-scheme" and "x-bearerFormat".
+	AddSecurityBearer(name, desc, bearerFormat string) ApiRoot
+
+	// AddSecurityOpenIDConnect adds `SecurityDefinition` of OpenID Connect
+	// with the discovery url. It is emitted as type apikey in the Authorization
+	// header, with extension "x-openIdConnectUrl".
+	AddSecurityOpenIDConnect(name, url string) ApiRoot
+
+	// AddSecurityMutualTLS adds `SecurityDefinition` of mutual TLS which
+	// authenticates clients by certificates. Swagger 2.0 has no such type,
+	// so it is emitted without type, only with extension "x-scheme".
+	AddSecurityMutualTLS(name, desc string) ApiRoot
+
+	// SetSecurity sets global Security for all operations which names are
+	// reigistered by AddSecurity... functions. Operations without their own
+	// Security and groups' Security apply it.
+	SetSecurity(names ...string) ApiRoot
+
+	// SetSecurityWithScope sets global Security with scopes for all
+	// operations which names are reigistered by AddSecurity... functions.
+	// Should only use when Security type is oauth2.
+	SetSecurityWithScope(s map[string][]string) ApiRoot
+
+	// DefineParameter adds a root-level `Parameter` which can be
+	// referenced by Api.UseParameter.
+	DefineParameter(name string, in ParamInType, p interface{}, desc string, required bool) ApiRoot
+
+	// DefineResponse adds a root-level `Response` which can be
+	// referenced by Api.UseResponse.
+	// Header must be struct type.
+	DefineResponse(name, desc string, schema interface{}, header interface{}) ApiRoot
+
+	// AddTag adds a tag which is not tied to an ApiGroup, it can be
+	// used by Api.AddTags.
+	AddTag(name, desc string, externalDocs *ExternalDocs) ApiRoot
+
+	// SetOperationIDFunc sets the function to generate operationId for
+	// operations which have no operationId set by Api.SetOperationId.
+	// Operations with duplicated generated operationIds use OperationIDFromPath.
+	SetOperationIDFunc(f OperationIDFunc) ApiRoot
+
+	// SetUndocumentedRoutes sets how to handle routes which are registered
+	// directly on the Echo instance or groups, default is UndocumentedIgnore.
+	SetUndocumentedRoutes(mode UndocumentedMode) ApiRoot
+
+	// SetStrictSecurity sets whether undeclared scopes and unused
+	// SecurityDefinitions fail the spec generation, default is false.
+	SetStrictSecurity(strict bool) ApiRoot
+
+	// SetTagOrder sets the order of tags in the spec. Tags which are
+	// not listed follow in their registration order.
+	SetTagOrder(names ...string) ApiRoot
+
+	// AddDocPath mounts another doc page and spec at docPath, which only show
+	// operations without audience and operations for any of the audiences.
+	// Hidden operations are not shown in any spec.
+	AddDocPath(docPath string, audiences []string, m ...echo.MiddlewareFunc) ApiRoot
+
+	// InjectOperation returns a middleware which stores the documented
+	// operation of the matched route and its effective Security in
+	// echo.Context, including hidden operations. Use OperationFromContext
+	// and SecurityFromContext to get them.
+	InjectOperation() echo.MiddlewareFunc
+
+	// EnforceSecurity returns a middleware which authenticates requests by
+	// the securities of the documented operation, or the global Security
+	// if the operation has none, with verifiers. Routes which are not
+	// documented by the ApiRoot, including routes of other ApiRoots, require
+	// the global Security, except the doc pages of the ApiRoot. Requests
+	// must satisfy all securities of any security requirement, otherwise
+	// they get 401, or 403 if credentials are valid but scopes are insufficient.
+	EnforceSecurity(v SecurityVerifiers) echo.MiddlewareFunc
+
+	// ValidateRequest returns a middleware which validates parameters and
+	// JSON body of requests against the documented operation, requests which
+	// fail the validation get a 400 response with ValidationErrors.
+	// Use it with Echo#Use for all operations, or pass it to the route
+	// methods for a single Api. Undocumented routes are not validated.
+	ValidateRequest() echo.MiddlewareFunc
+
+	// ValidateResponse returns a middleware which checks status code, headers
+	// and JSON body of responses against the documented operation, including
+	// properties which are not declared. Mismatches are passed to report,
+	// which logs a warning if it is nil. It is meant for development and tests.
+	ValidateResponse(report func(c echo.Context, err error)) echo.MiddlewareFunc
+
 	// SetUI sets UI setting.
 	// If DetachSpec is false, HideTop will not take effect
 	SetUI(ui UISetting) ApiRoot
@@ -83,6 +193,20 @@ type ApiRoot interface {
 	// SetScheme sets available protocol schemes.
 	SetScheme(schemes ...string) ApiRoot
 
+	// SetHost sets host of the spec instead of resolving it from requests.
+	SetHost(host string) ApiRoot
+
+	// SetBasePath sets basePath of the spec instead of resolving it from requests.
+	SetBasePath(basePath string) ApiRoot
+
+	// SetForwarded resolves host, basePath and schemes of the spec from
+	// "Forwarded", "X-Forwarded-Host", "X-Forwarded-Proto" and
+	// "X-Forwarded-Prefix" headers of requests from trusted proxies,
+	// which are IPs or CIDRs. DefaultTrustedProxies, which are loopback
+	// and private networks, are trusted if none is given.
+	// SetHost, SetBasePath and SetScheme take precedence.
+	SetForwarded(trustedProxies ...string) ApiRoot
+
 	// GetRaw returns raw `Swagger`. Only special case should use.
 	GetRaw() *Swagger
 
@@ -96,6 +220,15 @@ type ApiRoot interface {
 type ApiGroup interface {
 	ApiRouter
 
+	// Group overrides `Echo#Group()` and creates a nested ApiGroup, which
+	// inherits path prefix, middlewares, security and defaults of the ApiGroup.
+	Group(name, prefix string, m ...echo.MiddlewareFunc) ApiGroup
+
+	// SetFlatten makes a nested ApiGroup share the tag of its parent
+	// instead of emitting its own tag, which is listed under the tag of
+	// top-level ApiGroup by `x-tagGroups`.
+	SetFlatten() ApiGroup
+
 	// SetDescription sets description for ApiGroup.
 	SetDescription(desc string) ApiGroup
 
@@ -112,6 +245,35 @@ type ApiGroup interface {
 	// Should only use when Security type is oauth2.
 	SetSecurityWithScope(s map[string][]string) ApiGroup
 
+	// SetHidden hides all operations within the ApiGroup from the specs.
+	SetHidden() ApiGroup
+
+	// SetAudience sets audiences for all operations within the ApiGroup
+	// which have no audiences of their own.
+	SetAudience(labels ...string) ApiGroup
+
+	// AddParamHeader adds header parameter for all operations within the ApiGroup.
+	// Parameters with the same name and location added by Api take precedence.
+	AddParamHeader(p interface{}, name, desc string, required bool) ApiGroup
+
+	// AddParamQueryNested adds query parameters nested in p for all
+	// operations within the ApiGroup.
+	// P must be struct type.
+	AddParamQueryNested(p interface{}) ApiGroup
+
+	// AddResponse adds response for all operations within the ApiGroup.
+	// Responses with the same code added by Api take precedence.
+	// Header must be struct type.
+	AddResponse(code int, desc string, schema interface{}, header interface{}) ApiGroup
+
+	// SetRequestContentType sets request content types for all operations
+	// within the ApiGroup which have no request content types of their own.
+	SetRequestContentType(types ...string) ApiGroup
+
+	// SetResponseContentType sets response content types for all operations
+	// within the ApiGroup which have no response content types of their own.
+	SetResponseContentType(types ...string) ApiGroup
+
 	// EchoGroup returns the embedded `echo.Group` instance.
 	EchoGroup() *echo.Group
 }
@@ -155,6 +317,14 @@ type Api interface {
 	// Header must be struct type.
 	AddResponse(code int, desc string, schema interface{}, header interface{}) Api
 
+	// UseParameter adds a reference to a parameter which is
+	// defined by ApiRoot.DefineParameter.
+	UseParameter(name string) Api
+
+	// UseResponse adds a reference to a response which is
+	// defined by ApiRoot.DefineResponse.
+	UseResponse(code int, name string) Api
+
 	// SetRequestContentType sets request content types.
 	SetRequestContentType(types ...string) Api
 
@@ -164,6 +334,9 @@ type Api interface {
 	// SetOperationId sets operationId
 	SetOperationId(id string) Api
 
+	// AddTags adds tags besides the tag of ApiGroup.
+	AddTags(tags ...string) Api
+
 	// SetDeprecated marks Api as deprecated.
 	SetDeprecated() Api
 
@@ -185,23 +358,60 @@ type Api interface {
 	// Should only use when Security type is oauth2.
 	SetSecurityWithScope(s map[string][]string) Api
 
+	// SetNoSecurity makes Api public by an empty Security, which overrides
+	// the global Security, Security of groups and Api are ignored.
+	SetNoSecurity() Api
+
+	// SetHidden hides Api from the specs.
+	SetHidden() Api
+
+	// SetAudience sets audiences of Api, which is only shown in the specs
+	// mounted by ApiRoot.AddDocPath for any of the audiences.
+	SetAudience(labels ...string) Api
+
 	// Route returns the embedded `echo.Route` instance.
 	Route() *echo.Route
 }
 
 type routers struct {
-	apis []api
+	echo *echo.Echo
+	apis []*api
 	defs *RawDefineDic
 }
 
 type Root struct {
 	routers
-	spec   *Swagger
-	echo   *echo.Echo
-	groups []group
-	ui     UISetting
-	once   sync.Once
-	err    error
+	spec     *Swagger
+	groups   []*group
+	tags     []*Tag
+	tagOrder []string
+	security []map[string][]string
+	ui       UISetting
+	once     sync.Once
+	err      error
+	// routes and operationIds record transferred routes to detect duplicates
+	routes          map[string]*echo.Route
+	operationIds    map[string]*echo.Route
+	transferred     []*api
+	operationIDFunc OperationIDFunc
+	docMounts       []*docMount
+	undocumented    UndocumentedMode
+	// docAudiences records audiences of doc paths added by AddDocPath
+	docAudiences map[string][]string
+	hidden       map[*Operation]bool
+	audiences    map[*Operation][]string
+	// operations indexes operations by method and echo path for middlewares
+	operations map[string]*Operation
+	// host and basePath are set to the spec if they are not empty
+	host     string
+	basePath string
+	// forwarded resolves location of spec from proxy headers
+	forwarded      bool
+	trustedProxies []*net.IPNet
+	// strictSecurity reports undeclared scopes and unused SecurityDefinitions
+	strictSecurity bool
+	// docRoutes records method and path of doc pages for EnforceSecurity
+	docRoutes map[string]bool
 }
 
 type group struct {
@@ -209,13 +419,26 @@ type group struct {
 	echoGroup *echo.Group
 	security  []map[string][]string
 	tag       Tag
+	operation Operation
+	parent    *group
+	groups    []*group
+	flatten   bool
+	hidden    bool
+	audiences []string
 }
 
+// multiApi applies to all operations created by Any or Match.
+type multiApi []*api
+
 type api struct {
 	route     *echo.Route
 	defs      *RawDefineDic
 	security  []map[string][]string
 	operation Operation
+	hidden    bool
+	audiences []string
+	// noSecurity makes operation public, see Api.SetNoSecurity
+	noSecurity bool
 }
 
 // New creates ApiRoot instance.
@@ -232,19 +455,25 @@ func New(e *echo.Echo, docPath string, i *Info, m ...echo.MiddlewareFunc) ApiRoo
 	}
 	defs := make(RawDefineDic)
 	r := &Root{
-		echo: e,
 		spec: &Swagger{
 			Info:                i,
 			SecurityDefinitions: make(map[string]*SecurityDefinition),
 			Definitions:         make(map[string]*JSONSchema),
+			Parameters:          make(map[string]*Parameter),
+			Responses:           make(map[string]*Response),
 		},
 		routers: routers{
+			echo: e,
 			defs: &defs,
 		},
+		docAudiences: make(map[string][]string),
+		docRoutes:    make(map[string]bool),
+		hidden:       make(map[*Operation]bool),
+		audiences:    make(map[*Operation][]string),
 	}
 
-	e.GET(connectPath(docPath), r.docHandler(docPath), m...)
-	e.GET(connectPath(docPath, SpecName), r.specHandler(docPath), m...)
+	acquireRoutes(e)
+	r.mountDoc(docPath, m)
 	return r
 }
 
@@ -280,35 +509,38 @@ func (r *Root) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc)
 	return r.appendRoute(r.echo.PATCH(path, h, m...))
 }
 
+func (r *Root) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return r.appendRoute(r.echo.CONNECT(path, h, m...))
+}
+
+func (r *Root) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return r.appendRoute(r.echo.TRACE(path, h, m...))
+}
+
+func (r *Root) Any(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return r.appendRoutes(r.echo.Any(path, h, m...))
+}
+
+func (r *Root) Match(methods []string, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return r.appendRoutes(r.echo.Match(methods, path, h, m...))
+}
+
 func (r *Root) Group(name, prefix string, m ...echo.MiddlewareFunc) ApiGroup {
 	if name == "" {
 		panic("echoswagger: invalid name of ApiGroup")
 	}
-	echoGroup := r.echo.Group(prefix, m...)
-	group := group{
-		echoGroup: echoGroup,
-		routers: routers{
-			defs: r.defs,
-		},
-	}
-	group.tag = Tag{Name: name}
-	r.groups = append(r.groups, group)
-	return &r.groups[len(r.groups)-1]
+	g := newGroup(name, r.echo, r.echo.Group(prefix, m...), r.defs)
+	r.groups = append(r.groups, g)
+	return g
 }
 
 func (r *Root) BindGroup(name string, g *echo.Group) ApiGroup {
 	if name == "" {
 		panic("echoswagger: invalid name of ApiGroup")
 	}
-	group := group{
-		echoGroup: g,
-		routers: routers{
-			defs: r.defs,
-		},
-	}
-	group.tag = Tag{Name: name}
+	group := newGroup(name, r.echo, g, r.defs)
 	r.groups = append(r.groups, group)
-	return &r.groups[len(r.groups)-1]
+	return group
 }
 
 func (r *Root) SetRequestContentType(types ...string) ApiRoot {
@@ -371,8 +603,167 @@ func (r *Root) AddSecurityOAuth2(name, desc string, flow OAuth2FlowType, authori
 	return r
 }
 
+func (r *Root) AddSecurityOAuth2Flows(name, desc string, flows ...OAuth2Flow) ApiRoot {
+	if !r.checkSecurity(name) || len(flows) == 0 {
+		return r
+	}
+	scopes := make(map[string]string)
+	xFlows := make(map[string]interface{})
+	for _, f := range flows {
+		for k, v := range f.Scopes {
+			scopes[k] = v
+		}
+		xFlows[string(f.Flow)] = f
+	}
+	sd := &SecurityDefinition{
+		Type:             string(SecurityOAuth2),
+		Description:      desc,
+		Flow:             string(flows[0].Flow),
+		AuthorizationURL: flows[0].AuthorizationURL,
+		TokenURL:         flows[0].TokenURL,
+		Scopes:           scopes,
+		Extensions:       map[string]interface{}{"x-flows": xFlows},
+	}
+	r.spec.SecurityDefinitions[name] = sd
+	return r
+}
+
+func (r *Root) AddSecurityBearer(name, desc, bearerFormat string) ApiRoot {
+	if !r.checkSecurity(name) {
+		return r
+	}
+	sd := &SecurityDefinition{
+		Type:        string(SecurityAPIKey),
+		Description: desc,
+		Name:        echo.HeaderAuthorization,
+		In:          string(SecurityInHeader),
+		Extensions:  map[string]interface{}{"x-scheme": "bearer"},
+	}
+	if bearerFormat != "" {
+		sd.Extensions["x-bearerFormat"] = bearerFormat
+	}
+	r.spec.SecurityDefinitions[name] = sd
+	return r
+}
+
+func (r *Root) AddSecurityOpenIDConnect(name, url string) ApiRoot {
+	if !r.checkSecurity(name) {
+		return r
+	}
+	sd := &SecurityDefinition{
+		Type:       string(SecurityAPIKey),
+		Name:       echo.HeaderAuthorization,
+		In:         string(SecurityInHeader),
+		Extensions: map[string]interface{}{"x-openIdConnectUrl": url},
+	}
+	r.spec.SecurityDefinitions[name] = sd
+	return r
+}
+
+func (r *Root) AddSecurityMutualTLS(name, desc string) ApiRoot {
+	if !r.checkSecurity(name) {
+		return r
+	}
+	sd := &SecurityDefinition{
+		Description: desc,
+		Extensions:  map[string]interface{}{"x-scheme": "mutualTLS"},
+	}
+	r.spec.SecurityDefinitions[name] = sd
+	return r
+}
+
+func (r *Root) SetSecurity(names ...string) ApiRoot {
+	if len(names) == 0 {
+		return r
+	}
+	r.security = setSecurity(r.security, names...)
+	return r
+}
+
+func (r *Root) SetSecurityWithScope(s map[string][]string) ApiRoot {
+	r.security = setSecurityWithScope(r.security, s)
+	return r
+}
+
+func (r *Root) DefineParameter(name string, in ParamInType, p interface{}, desc string, required bool) ApiRoot {
+	if name == "" {
+		return r
+	}
+	if _, ok := r.spec.Parameters[name]; ok {
+		return r
+	}
+	var pm *Parameter
+	if in == ParamInBody {
+		pm = r.defs.genBodyParam(p, name, desc, required)
+	} else {
+		pm = genParam(p, in, name, desc, required)
+	}
+	r.spec.Parameters[name] = pm
+	return r
+}
+
+func (r *Root) DefineResponse(name, desc string, schema interface{}, header interface{}) ApiRoot {
+	if name == "" {
+		return r
+	}
+	if _, ok := r.spec.Responses[name]; ok {
+		return r
+	}
+	r.spec.Responses[name] = r.defs.genResponse(desc, schema, header)
+	return r
+}
+
+func (r *Root) AddTag(name, desc string, externalDocs *ExternalDocs) ApiRoot {
+	if name == "" {
+		return r
+	}
+	for _, t := range r.tags {
+		if t.Name == name {
+			return r
+		}
+	}
+	r.tags = append(r.tags, &Tag{
+		Name:         name,
+		Description:  desc,
+		ExternalDocs: externalDocs,
+	})
+	return r
+}
+
+func (r *Root) SetOperationIDFunc(f OperationIDFunc) ApiRoot {
+	r.operationIDFunc = f
+	return r
+}
+
+func (r *Root) SetUndocumentedRoutes(mode UndocumentedMode) ApiRoot {
+	r.undocumented = mode
+	return r
+}
+
+func (r *Root) SetStrictSecurity(strict bool) ApiRoot {
+	r.strictSecurity = strict
+	return r
+}
+
+func (r *Root) SetTagOrder(names ...string) ApiRoot {
+	r.tagOrder = names
+	return r
+}
+
+func (r *Root) AddDocPath(docPath string, audiences []string, m ...echo.MiddlewareFunc) ApiRoot {
+	r.docAudiences[docKey(docPath)] = audiences
+	r.mountDoc(docPath, m)
+	return r
+}
+
 func (r *Root) SetUI(ui UISetting) ApiRoot {
+	if _, err := ui.config(); err != nil {
+		panic("echoswagger: invalid RawConfig of UISetting")
+	}
 	r.ui = ui
+	for _, d := range r.docMounts {
+		r.mountAssets(d)
+	}
 	return r
 }
 
@@ -386,6 +777,25 @@ func (r *Root) SetScheme(schemes ...string) ApiRoot {
 	return r
 }
 
+func (r *Root) SetHost(host string) ApiRoot {
+	r.host = host
+	return r
+}
+
+func (r *Root) SetBasePath(basePath string) ApiRoot {
+	if basePath != "" {
+		basePath = connectPath(basePath)
+	}
+	r.basePath = basePath
+	return r
+}
+
+func (r *Root) SetForwarded(trustedProxies ...string) ApiRoot {
+	r.forwarded = true
+	r.trustedProxies = parseTrustedProxies(trustedProxies)
+	return r
+}
+
 func (r *Root) GetRaw() *Swagger {
 	return r.spec
 }
@@ -400,51 +810,68 @@ func (r *Root) Echo() *echo.Echo {
 }
 
 func (g *group) Add(method, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.Add(method, path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.Add(method, path, h, m...))
 }
 
 func (g *group) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.GET(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.GET(path, h, m...))
 }
 
 func (g *group) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.POST(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.POST(path, h, m...))
 }
 
 func (g *group) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.PUT(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.PUT(path, h, m...))
 }
 
 func (g *group) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.DELETE(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.DELETE(path, h, m...))
 }
 
 func (g *group) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.OPTIONS(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.OPTIONS(path, h, m...))
 }
 
 func (g *group) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.HEAD(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.HEAD(path, h, m...))
 }
 
 func (g *group) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
-	a := g.appendRoute(g.echoGroup.PATCH(path, h, m...))
-	a.operation.Tags = []string{g.tag.Name}
-	return a
+	return g.appendRoute(g.echoGroup.PATCH(path, h, m...))
+}
+
+func (g *group) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return g.appendRoute(g.echoGroup.CONNECT(path, h, m...))
+}
+
+func (g *group) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return g.appendRoute(g.echoGroup.TRACE(path, h, m...))
+}
+
+func (g *group) Any(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return g.appendRoutes(g.echoGroup.Any(path, h, m...))
+}
+
+func (g *group) Match(methods []string, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) Api {
+	return g.appendRoutes(g.echoGroup.Match(methods, path, h, m...))
+}
+
+func (g *group) Group(name, prefix string, m ...echo.MiddlewareFunc) ApiGroup {
+	if name == "" {
+		panic("echoswagger: invalid name of ApiGroup")
+	}
+	child := newGroup(name, g.echo, g.echoGroup.Group(prefix, m...), g.defs)
+	child.parent = g
+	g.groups = append(g.groups, child)
+	return child
+}
+
+func (g *group) SetFlatten() ApiGroup {
+	if g.parent != nil {
+		g.flatten = true
+	}
+	return g
 }
 
 func (g *group) SetDescription(desc string) ApiGroup {
@@ -473,6 +900,42 @@ func (g *group) SetSecurityWithScope(s map[string][]string) ApiGroup {
 	return g
 }
 
+func (g *group) SetHidden() ApiGroup {
+	g.hidden = true
+	return g
+}
+
+func (g *group) SetAudience(labels ...string) ApiGroup {
+	g.audiences = labels
+	return g
+}
+
+func (g *group) AddParamHeader(p interface{}, name, desc string, required bool) ApiGroup {
+	g.operation.addParams(p, ParamInHeader, name, desc, required, false)
+	return g
+}
+
+func (g *group) AddParamQueryNested(p interface{}) ApiGroup {
+	g.operation.addParams(p, ParamInQuery, "", "", false, true)
+	return g
+}
+
+func (g *group) AddResponse(code int, desc string, schema interface{}, header interface{}) ApiGroup {
+	cstr := strconv.Itoa(code)
+	g.operation.Responses[cstr] = g.defs.genResponse(desc, schema, header)
+	return g
+}
+
+func (g *group) SetRequestContentType(types ...string) ApiGroup {
+	g.operation.Consumes = types
+	return g
+}
+
+func (g *group) SetResponseContentType(types ...string) ApiGroup {
+	g.operation.Produces = types
+	return g
+}
+
 func (g *group) EchoGroup() *echo.Group {
 	return g.echoGroup
 }
@@ -526,28 +989,23 @@ func (a *api) AddParamFile(name, desc string, required bool) Api {
 }
 
 func (a *api) AddResponse(code int, desc string, schema interface{}, header interface{}) Api {
-	r := &Response{
-		Description: desc,
-	}
-
-	st := reflect.TypeOf(schema)
-	if st != nil {
-		if !isValidSchema(st, false) {
-			panic("echoswagger: invalid response schema")
-		}
-		r.Schema = a.defs.genSchema(reflect.ValueOf(schema))
-	}
+	cstr := strconv.Itoa(code)
+	a.operation.Responses[cstr] = a.defs.genResponse(desc, schema, header)
+	return a
+}
 
-	ht := reflect.TypeOf(header)
-	if ht != nil {
-		if !isValidParam(reflect.TypeOf(header), true, false) {
-			panic("echoswagger: invalid response header")
-		}
-		r.Headers = a.genHeader(reflect.ValueOf(header))
-	}
+func (a *api) UseParameter(name string) Api {
+	a.operation.Parameters = append(a.operation.Parameters, &Parameter{
+		Ref: ParamPrefix + name,
+	})
+	return a
+}
 
+func (a *api) UseResponse(code int, name string) Api {
 	cstr := strconv.Itoa(code)
-	a.operation.Responses[cstr] = r
+	a.operation.Responses[cstr] = &Response{
+		Ref: RespPrefix + name,
+	}
 	return a
 }
 
@@ -566,6 +1024,15 @@ func (a *api) SetOperationId(id string) Api {
 	return a
 }
 
+func (a *api) AddTags(tags ...string) Api {
+	for _, t := range tags {
+		if t != "" && !contains(a.operation.Tags, t) {
+			a.operation.Tags = append(a.operation.Tags, t)
+		}
+	}
+	return a
+}
+
 func (a *api) SetDeprecated() Api {
 	a.operation.Deprecated = true
 	return a
@@ -602,6 +1069,216 @@ func (a *api) SetSecurityWithScope(s map[string][]string) Api {
 	return a
 }
 
+func (a *api) SetNoSecurity() Api {
+	a.noSecurity = true
+	return a
+}
+
+func (a *api) SetHidden() Api {
+	a.hidden = true
+	return a
+}
+
+func (a *api) SetAudience(labels ...string) Api {
+	a.audiences = labels
+	return a
+}
+
 func (a *api) Route() *echo.Route {
 	return a.route
 }
+
+func (m multiApi) AddParamPath(p interface{}, name, desc string) Api {
+	for _, a := range m {
+		a.AddParamPath(p, name, desc)
+	}
+	return m
+}
+
+func (m multiApi) AddParamPathNested(p interface{}) Api {
+	for _, a := range m {
+		a.AddParamPathNested(p)
+	}
+	return m
+}
+
+func (m multiApi) AddParamQuery(p interface{}, name, desc string, required bool) Api {
+	for _, a := range m {
+		a.AddParamQuery(p, name, desc, required)
+	}
+	return m
+}
+
+func (m multiApi) AddParamQueryNested(p interface{}) Api {
+	for _, a := range m {
+		a.AddParamQueryNested(p)
+	}
+	return m
+}
+
+func (m multiApi) AddParamForm(p interface{}, name, desc string, required bool) Api {
+	for _, a := range m {
+		a.AddParamForm(p, name, desc, required)
+	}
+	return m
+}
+
+func (m multiApi) AddParamFormNested(p interface{}) Api {
+	for _, a := range m {
+		a.AddParamFormNested(p)
+	}
+	return m
+}
+
+func (m multiApi) AddParamHeader(p interface{}, name, desc string, required bool) Api {
+	for _, a := range m {
+		a.AddParamHeader(p, name, desc, required)
+	}
+	return m
+}
+
+func (m multiApi) AddParamHeaderNested(p interface{}) Api {
+	for _, a := range m {
+		a.AddParamHeaderNested(p)
+	}
+	return m
+}
+
+func (m multiApi) AddParamBody(p interface{}, name, desc string, required bool) Api {
+	for _, a := range m {
+		a.AddParamBody(p, name, desc, required)
+	}
+	return m
+}
+
+func (m multiApi) AddParamFile(name, desc string, required bool) Api {
+	for _, a := range m {
+		a.AddParamFile(name, desc, required)
+	}
+	return m
+}
+
+func (m multiApi) AddResponse(code int, desc string, schema interface{}, header interface{}) Api {
+	for _, a := range m {
+		a.AddResponse(code, desc, schema, header)
+	}
+	return m
+}
+
+func (m multiApi) UseParameter(name string) Api {
+	for _, a := range m {
+		a.UseParameter(name)
+	}
+	return m
+}
+
+func (m multiApi) UseResponse(code int, name string) Api {
+	for _, a := range m {
+		a.UseResponse(code, name)
+	}
+	return m
+}
+
+func (m multiApi) SetRequestContentType(types ...string) Api {
+	for _, a := range m {
+		a.SetRequestContentType(types...)
+	}
+	return m
+}
+
+func (m multiApi) SetResponseContentType(types ...string) Api {
+	for _, a := range m {
+		a.SetResponseContentType(types...)
+	}
+	return m
+}
+
+// SetOperationId sets operationId suffixed with the method of each
+// operation, so that operationIds stay unique.
+func (m multiApi) SetOperationId(id string) Api {
+	for _, a := range m {
+		if len(m) == 1 {
+			a.SetOperationId(id)
+		} else {
+			a.SetOperationId(id + strings.Title(strings.ToLower(a.route.Method)))
+		}
+	}
+	return m
+}
+
+func (m multiApi) AddTags(tags ...string) Api {
+	for _, a := range m {
+		a.AddTags(tags...)
+	}
+	return m
+}
+
+func (m multiApi) SetDeprecated() Api {
+	for _, a := range m {
+		a.SetDeprecated()
+	}
+	return m
+}
+
+func (m multiApi) SetDescription(desc string) Api {
+	for _, a := range m {
+		a.SetDescription(desc)
+	}
+	return m
+}
+
+func (m multiApi) SetExternalDocs(desc, url string) Api {
+	for _, a := range m {
+		a.SetExternalDocs(desc, url)
+	}
+	return m
+}
+
+func (m multiApi) SetSummary(summary string) Api {
+	for _, a := range m {
+		a.SetSummary(summary)
+	}
+	return m
+}
+
+func (m multiApi) SetSecurity(names ...string) Api {
+	for _, a := range m {
+		a.SetSecurity(names...)
+	}
+	return m
+}
+
+func (m multiApi) SetSecurityWithScope(s map[string][]string) Api {
+	for _, a := range m {
+		a.SetSecurityWithScope(s)
+	}
+	return m
+}
+
+func (m multiApi) SetNoSecurity() Api {
+	for _, a := range m {
+		a.SetNoSecurity()
+	}
+	return m
+}
+
+func (m multiApi) SetHidden() Api {
+	for _, a := range m {
+		a.SetHidden()
+	}
+	return m
+}
+
+func (m multiApi) SetAudience(labels ...string) Api {
+	for _, a := range m {
+		a.SetAudience(labels...)
+	}
+	return m
+}
+
+func (m multiApi) Route() *echo.Route {
+	if len(m) == 0 {
+		return nil
+	}
+	return m[0].route
+}
@@ -1,8 +1,13 @@
 package echoswagger
 
 import (
+	"encoding/json"
+	"html/template"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -41,7 +46,7 @@ func TestNew(t *testing.T) {
 			echo:        echo.New(),
 			docPath:     "doc/",
 			info:        nil,
-			expectPaths: []string{"/doc/", "/doc/swagger.json"},
+			expectPaths: []string{"/doc/", "/doc/swagger.json", "/doc/oauth2-redirect.html", "/doc/assets/*"},
 			panic:       false,
 			name:        "Normal",
 		},
@@ -54,7 +59,7 @@ func TestNew(t *testing.T) {
 					URL: "https://github.com/pangpanglabs/echoswagger",
 				},
 			},
-			expectPaths: []string{"/doc", "/doc/swagger.json"},
+			expectPaths: []string{"/doc", "/doc/swagger.json", "/doc/oauth2-redirect.html", "/doc/assets/*"},
 			panic:       false,
 			name:        "Path slash suffix",
 		},
@@ -84,9 +89,9 @@ func TestNew(t *testing.T) {
 				}
 
 				assert.NotNil(t, r.echo)
-				assert.Len(t, r.echo.Routes(), 2)
+				assert.Len(t, r.echo.Routes(), 4)
 				res := r.echo.Routes()
-				paths := []string{res[0].Path, res[1].Path}
+				paths := []string{res[0].Path, res[1].Path, res[2].Path, res[3].Path}
 				assert.ElementsMatch(t, paths, tt.expectPaths)
 			}
 		})
@@ -95,25 +100,32 @@ func TestNew(t *testing.T) {
 
 func TestPath(t *testing.T) {
 	tests := []struct {
-		docInput              string
-		docOutput, specOutput string
-		name                  string
+		docInput                              string
+		docOutput, specOutput, redirectOutput string
+		assetsOutput                          string
+		name                                  string
 	}{
 		{
-			docInput:   "doc/",
-			docOutput:  "/doc/",
-			specOutput: "/doc/swagger.json",
-			name:       "A",
+			docInput:       "doc/",
+			docOutput:      "/doc/",
+			specOutput:     "/doc/swagger.json",
+			redirectOutput: "/doc/oauth2-redirect.html",
+			assetsOutput:   "/doc/assets/*",
+			name:           "A",
 		}, {
-			docInput:   "",
-			docOutput:  "/",
-			specOutput: "/swagger.json",
-			name:       "B",
+			docInput:       "",
+			docOutput:      "/",
+			specOutput:     "/swagger.json",
+			redirectOutput: "/oauth2-redirect.html",
+			assetsOutput:   "/assets/*",
+			name:           "B",
 		}, {
-			docInput:   "/doc",
-			docOutput:  "/doc",
-			specOutput: "/doc/swagger.json",
-			name:       "C",
+			docInput:       "/doc",
+			docOutput:      "/doc",
+			specOutput:     "/doc/swagger.json",
+			redirectOutput: "/doc/oauth2-redirect.html",
+			assetsOutput:   "/doc/assets/*",
+			name:           "C",
 		},
 	}
 	for _, tt := range tests {
@@ -121,10 +133,10 @@ func TestPath(t *testing.T) {
 			apiRoot := New(echo.New(), tt.docInput, nil)
 			r := apiRoot.(*Root)
 			assert.NotNil(t, r.echo)
-			assert.Len(t, r.echo.Routes(), 2)
+			assert.Len(t, r.echo.Routes(), 4)
 			res := r.echo.Routes()
-			paths := []string{res[0].Path, res[1].Path}
-			assert.ElementsMatch(t, paths, []string{tt.docOutput, tt.specOutput})
+			paths := []string{res[0].Path, res[1].Path, res[2].Path, res[3].Path}
+			assert.ElementsMatch(t, paths, []string{tt.docOutput, tt.specOutput, tt.redirectOutput, tt.assetsOutput})
 		})
 	}
 }
@@ -151,6 +163,41 @@ func TestGroup(t *testing.T) {
 	})
 }
 
+func TestNestedGroup(t *testing.T) {
+	r := prepareApiRoot()
+	r.AddSecurityBasic("Basic", "")
+	var h echo.HandlerFunc
+
+	g := r.Group("Admin", "/admin").SetSecurity("Basic")
+	users := g.Group("Users", "/users")
+	users.GET("/:id", h)
+	roles := g.Group("Roles", "/roles").SetFlatten()
+	roles.GET("", h)
+
+	assert.Panics(t, func() {
+		g.Group("", "/empty")
+	})
+	assert.Equal(t, "/admin/users/:id", users.(*group).apis[0].route.Path)
+	assert.Equal(t, "/admin/roles", roles.(*group).apis[0].route.Path)
+	assert.True(t, roles.(*group).flatten)
+	assert.False(t, g.SetFlatten().(*group).flatten)
+
+	c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+	if assert.NoError(t, r.(*Root).genSpec(c)) {
+		s := r.(*Root).spec
+		u := s.Paths["/admin/users/{id}"].(*Path).Get
+		assert.Equal(t, []string{"Users"}, u.Tags)
+		assert.Equal(t, []map[string][]string{{"Basic": {}}}, u.Security)
+
+		ro := s.Paths["/admin/roles"].(*Path).Get
+		assert.Equal(t, []string{"Admin"}, ro.Tags)
+		assert.Equal(t, []map[string][]string{{"Basic": {}}}, ro.Security)
+
+		assert.Len(t, s.Tags, 2)
+		assert.Equal(t, []*TagGroup{{Name: "Admin", Tags: []string{"Admin", "Users"}}}, s.TagGroups)
+	}
+}
+
 func TestBindGroup(t *testing.T) {
 	r := prepareApiRoot()
 	e := r.Echo()
@@ -187,7 +234,9 @@ func TestRouters(t *testing.T) {
 	r.OPTIONS("/:id", h)
 	r.HEAD("/:id", h)
 	r.PATCH("/:id", h)
-	assert.Len(t, r.(*Root).apis, 7)
+	r.CONNECT("/:id", h)
+	r.TRACE("/:id", h)
+	assert.Len(t, r.(*Root).apis, 9)
 
 	g := prepareApiGroup()
 	g.GET("/:id", h)
@@ -197,7 +246,42 @@ func TestRouters(t *testing.T) {
 	g.OPTIONS("/:id", h)
 	g.HEAD("/:id", h)
 	g.PATCH("/:id", h)
-	assert.Len(t, g.(*group).apis, 7)
+	g.CONNECT("/:id", h)
+	g.TRACE("/:id", h)
+	assert.Len(t, g.(*group).apis, 9)
+}
+
+func TestAnyMatch(t *testing.T) {
+	var h echo.HandlerFunc
+	t.Run("Any", func(t *testing.T) {
+		r := prepareApiRoot()
+		a := r.Any("/any", h).
+			AddParamQuery("", "q", "", false).
+			AddResponse(http.StatusOK, "successful", nil, nil).
+			SetOperationId("any").
+			SetSummary("Any methods")
+		assert.NotNil(t, a.Route())
+		assert.Equal(t, "/any", a.Route().Path)
+		assert.Len(t, r.(*Root).apis, len(a.(multiApi)))
+		for _, o := range r.(*Root).apis {
+			assert.Len(t, o.operation.Parameters, 1)
+			assert.Len(t, o.operation.Responses, 1)
+			assert.Equal(t, "Any methods", o.operation.Summary)
+		}
+		assert.Equal(t, "anyGet", r.(*Root).apis[2].operation.OperationID)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		g := prepareApiGroup()
+		a := g.Match([]string{echo.GET, echo.HEAD}, "/match", h).SetDeprecated()
+		assert.Len(t, g.(*group).apis, 2)
+		assert.Equal(t, echo.GET, a.Route().Method)
+		assert.Equal(t, "/g/match", g.(*group).apis[1].route.Path)
+		assert.True(t, g.(*group).apis[1].operation.Deprecated)
+
+		g.Match([]string{echo.PUT}, "/single", h).SetOperationId("single")
+		assert.Equal(t, "single", g.(*group).apis[2].operation.OperationID)
+	})
 }
 
 func TestAddParam(t *testing.T) {
@@ -351,9 +435,62 @@ func TestAddResponse(t *testing.T) {
 	})
 }
 
+func TestDefineParameter(t *testing.T) {
+	r := prepareApiRoot()
+	r.DefineParameter("X-Request-ID", ParamInHeader, "", "Request ID", true).
+		DefineParameter("limit", ParamInQuery, 0, "Page size", false).
+		DefineParameter("limit", ParamInQuery, "", "Repeat", false).
+		DefineParameter("", ParamInQuery, "", "Empty", false)
+
+	params := r.(*Root).spec.Parameters
+	assert.Len(t, params, 2)
+	assert.Equal(t, &Parameter{
+		Name:        "X-Request-ID",
+		In:          string(ParamInHeader),
+		Description: "Request ID",
+		Required:    true,
+		Type:        "string",
+		Format:      "string",
+	}, params["X-Request-ID"])
+	assert.Equal(t, "integer", params["limit"].Type)
+	assert.Equal(t, "Page size", params["limit"].Description)
+
+	assert.Panics(t, func() {
+		r.DefineParameter("filter", ParamInQuery, struct{}{}, "", false)
+	})
+
+	var h echo.HandlerFunc
+	a := r.GET("/", h).UseParameter("limit")
+	assert.Len(t, a.(*api).operation.Parameters, 1)
+	assert.Equal(t, "#/parameters/limit", a.(*api).operation.Parameters[0].Ref)
+}
+
+func TestDefineResponse(t *testing.T) {
+	type body struct {
+		Message string `json:"message"`
+	}
+	r := prepareApiRoot()
+	r.DefineResponse("Unauthorized", "unauthorized", body{}, nil).
+		DefineResponse("Unauthorized", "repeat", nil, nil)
+
+	resps := r.(*Root).spec.Responses
+	assert.Len(t, resps, 1)
+	assert.Equal(t, "unauthorized", resps["Unauthorized"].Description)
+	assert.Equal(t, "#/definitions/body", resps["Unauthorized"].Schema.Ref)
+
+	assert.Panics(t, func() {
+		r.DefineResponse("Invalid", "invalid", nil, time.Now())
+	})
+
+	var h echo.HandlerFunc
+	a := r.GET("/", h).UseResponse(http.StatusUnauthorized, "Unauthorized")
+	assert.Equal(t, &Response{Ref: "#/responses/Unauthorized"}, a.(*api).operation.Responses["401"])
+}
+
 func TestUI(t *testing.T) {
 	t.Run("DefaultCDN", func(t *testing.T) {
-		r := New(echo.New(), "doc/", nil)
+		e := echo.New()
+		r := New(e, "doc/", nil)
 		se := r.(*Root)
 		req := httptest.NewRequest(echo.GET, "/doc/", nil)
 		rec := httptest.NewRecorder()
@@ -362,8 +499,14 @@ func TestUI(t *testing.T) {
 
 		if assert.NoError(t, h(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
-			assert.Contains(t, rec.Body.String(), DefaultCDN)
+			assert.Contains(t, rec.Body.String(), `src="assets/swagger-ui-bundle.js"`)
+			assert.NotContains(t, rec.Body.String(), DefaultCDN)
 		}
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/assets/swagger-ui-bundle.js", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "SwaggerUIBundle")
 	})
 
 	t.Run("SetUI", func(t *testing.T) {
@@ -397,6 +540,191 @@ func TestUI(t *testing.T) {
 			assert.Contains(t, rec.Body.String(), "#swagger-ui>.swagger-container>.topbar")
 		}
 	})
+
+	t.Run("Assets", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "swagger-ui-dist")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer os.RemoveAll(dir)
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "swagger-ui.css"), []byte("body{}"), 0644))
+
+		e := echo.New()
+		r := New(e, "doc/", nil)
+		r.SetUI(UISetting{Assets: http.Dir(dir)})
+		r.AddDocPath("/internal/doc", nil)
+
+		tests := []struct {
+			target string
+			code   int
+			body   string
+		}{
+			{"/doc/", http.StatusOK, `href="assets/swagger-ui.css"`},
+			{"/internal/doc", http.StatusOK, `href="doc/assets/swagger-ui.css"`},
+			{"/doc/assets/swagger-ui.css", http.StatusOK, "body{}"},
+			{"/internal/doc/assets/swagger-ui.css", http.StatusOK, "body{}"},
+			{"/doc/assets/", http.StatusNotFound, ""},
+			{"/doc/assets/../swagger-ui.css", http.StatusOK, "body{}"},
+			{"/doc/assets/missing.js", http.StatusNotFound, ""},
+		}
+		for _, tt := range tests {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, tt.target, nil))
+			assert.Equal(t, tt.code, rec.Code, tt.target)
+			assert.Contains(t, rec.Body.String(), tt.body, tt.target)
+			if tt.code == http.StatusOK && tt.body == "body{}" {
+				assert.Equal(t, "public, max-age=86400", rec.Header().Get("Cache-Control"))
+			}
+		}
+
+		r.SetUI(UISetting{Assets: http.Dir(dir), CDN: DefaultCDN})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), DefaultCDN+"/swagger-ui.css")
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		e := echo.New()
+		r := New(e, "doc/", nil)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), "}, {}))")
+
+		depth, noValidator, deepLinking := -1, "", false
+		r.SetUI(UISetting{
+			DeepLinking:              &deepLinking,
+			DocExpansion:             "none",
+			DefaultModelsExpandDepth: &depth,
+			Filter:                   true,
+			PersistAuthorization:     true,
+			DisplayOperationId:       true,
+			TryItOutEnabled:          true,
+			SupportedSubmitMethods:   []string{"get"},
+			OperationsSorter:         "alpha",
+			TagsSorter:               "alpha",
+			RequestSnippetsEnabled:   true,
+			ValidatorUrl:             &noValidator,
+			RawConfig:                json.RawMessage(`{"tagsSorter":"none","maxDisplayedTags":5,"x":"</script>"}`),
+		})
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), `}, {"deepLinking":false,"defaultModelsExpandDepth":-1,"displayOperationId":true,`+
+			`"docExpansion":"none","filter":true,"maxDisplayedTags":5,"operationsSorter":"alpha","persistAuthorization":true,`+
+			`"requestSnippetsEnabled":true,"supportedSubmitMethods":["get"],"tagsSorter":"none","tryItOutEnabled":true,`+
+			`"validatorUrl":null,"x":"\u003c/script\u003e"}))`)
+
+		assert.Panics(t, func() {
+			r.SetUI(UISetting{RawConfig: json.RawMessage(`[]`)})
+		})
+	})
+
+	t.Run("Renderer", func(t *testing.T) {
+		tests := []struct {
+			ui       UISetting
+			contains []string
+		}{
+			{
+				ui:       UISetting{Renderer: UIReDoc},
+				contains: []string{DefaultReDocCDN + "/redoc.standalone.js", `Redoc.init(spec || specUrl, {}, document.getElementById("redoc"))`, `var specStr = "{`},
+			},
+			{
+				ui:       UISetting{Renderer: UIRapiDoc, DetachSpec: true, RawConfig: json.RawMessage(`{"theme":"dark"}`)},
+				contains: []string{DefaultRapiDocCDN + "/rapidoc-min.js", `<rapi-doc id="rapidoc">`, `var config = {"theme":"dark"}`, `var specStr = ""`},
+			},
+			{
+				ui:       UISetting{Renderer: UIRapiDoc, CDN: "https://example.com/rapidoc"},
+				contains: []string{"https://example.com/rapidoc/rapidoc-min.js"},
+			},
+			{
+				ui:       UISetting{Renderer: "unknown", Filter: true},
+				contains: []string{"assets/swagger-ui-bundle.js", `}, {"filter":true}))`},
+			},
+		}
+		for _, tt := range tests {
+			e := echo.New()
+			New(e, "doc/", nil).SetUI(tt.ui)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			for _, c := range tt.contains {
+				assert.Contains(t, rec.Body.String(), c)
+			}
+		}
+	})
+
+	t.Run("Branding", func(t *testing.T) {
+		for _, renderer := range []UIRenderer{UISwaggerUI, UIReDoc, UIRapiDoc} {
+			e := echo.New()
+			New(e, "doc/", nil).SetUI(UISetting{
+				Renderer:   renderer,
+				Logo:       "/static/logo.png",
+				Favicon:    "/static/favicon.ico",
+				CSS:        "body{color:red}",
+				HeadHTML:   `<meta name="robots" content="noindex">`,
+				HeaderHTML: "<nav>Portal</nav>",
+				FooterHTML: "<footer>Copyright</footer>",
+				Scripts:    []string{"https://example.com/analytics.js"},
+				Nonce: func(c echo.Context) string {
+					return "abc"
+				},
+			})
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+			body := rec.Body.String()
+			assert.Contains(t, body, `<link rel="icon" href="/static/favicon.ico" />`, renderer)
+			assert.NotContains(t, body, "favicon-32x32.png", renderer)
+			assert.Contains(t, body, `<style nonce="abc">body{color:red}</style>`, renderer)
+			assert.Contains(t, body, `<meta name="robots" content="noindex">`, renderer)
+			assert.Contains(t, body, `<img class="echoswagger-logo" src="/static/logo.png" alt="Project APIs" />`, renderer)
+			assert.Contains(t, body, "<nav>Portal</nav>", renderer)
+			assert.Contains(t, body, "<footer>Copyright</footer>", renderer)
+			assert.Contains(t, body, `<script src="https://example.com/analytics.js" nonce="abc"></script>`, renderer)
+			assert.NotContains(t, body, "<script>", renderer)
+		}
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		e := echo.New()
+		tmpl := template.Must(template.New("custom").Parse(`<h1>{{.title}}</h1><a href="{{.specName}}">{{.cdn}}</a>{{.docPath}}`))
+		New(e, "doc/", nil).SetUI(UISetting{Template: tmpl, DetachSpec: true})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Equal(t, `<h1>Project APIs</h1><a href="swagger.json">assets</a>doc/`, rec.Body.String())
+
+		tmpl = template.Must(template.New("custom").Parse(`{{.missing.field}}`))
+		New(e, "doc2/", nil).SetUI(UISetting{Template: tmpl.Option("missingkey=error")})
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc2/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("OAuth", func(t *testing.T) {
+		e := echo.New()
+		r := New(e, "doc/", nil)
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/oauth2-redirect.html", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "window.opener.swaggerUIRedirectOauth2")
+
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), `var redirectPath = "oauth2-redirect.html"`)
+		assert.Contains(t, rec.Body.String(), "oauth2RedirectUrl:")
+		assert.NotContains(t, rec.Body.String(), "ui.initOAuth")
+
+		r.SetUI(UISetting{OAuth: &OAuthSetting{
+			ClientId: "client",
+			AppName:  "Pets",
+			Scopes:   []string{"read:pets"},
+
+			UsePkceWithAuthorizationCodeGrant: true,
+		}})
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(echo.GET, "/doc/", nil))
+		assert.Contains(t, rec.Body.String(), `ui.initOAuth({"clientId":"client","appName":"Pets","scopes":["read:pets"],"usePkceWithAuthorizationCodeGrant":true})`)
+	})
 }
 
 func TestScheme(t *testing.T) {
@@ -458,6 +786,34 @@ func TestOperationId(t *testing.T) {
 	assert.Equal(t, a.(*api).operation.OperationID, id)
 }
 
+func TestTags(t *testing.T) {
+	r := prepareApiRoot()
+	docs := &ExternalDocs{URL: "http://127.0.0.1/billing"}
+	r.AddTag("billing", "Billing APIs", docs).
+		AddTag("billing", "Repeat", nil).
+		AddTag("", "Empty", nil).
+		AddTag("orders", "Orders APIs", nil).
+		SetTagOrder("billing", "orders")
+	assert.Len(t, r.(*Root).tags, 2)
+
+	var h echo.HandlerFunc
+	a := r.Group("orders", "/orders").POST("/:id/pay", h).AddTags("billing", "billing", "")
+	r.Group("users", "/users").GET("", h).AddTags("users")
+	assert.Equal(t, []string{"billing"}, a.(*api).operation.Tags)
+
+	c := r.(*Root).echo.NewContext(httptest.NewRequest(echo.GET, "/", nil), httptest.NewRecorder())
+	if assert.NoError(t, r.(*Root).genSpec(c)) {
+		s := r.(*Root).spec
+		assert.Equal(t, []string{"orders", "billing"}, s.Paths["/orders/{id}/pay"].(*Path).Post.Tags)
+		assert.Equal(t, []string{"users"}, s.Paths["/users"].(*Path).Get.Tags)
+		assert.Equal(t, []*Tag{
+			{Name: "billing", Description: "Billing APIs", ExternalDocs: docs},
+			{Name: "orders", Description: "Orders APIs"},
+			{Name: "users"},
+		}, s.Tags)
+	}
+}
+
 func TestDeprecated(t *testing.T) {
 	a := prepareApi()
 	a.SetDeprecated()